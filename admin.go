@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
+)
+
+const adminUsage = "usage: ginblog [-config path] admin <username>  (新用户的密码从标准输入读取)"
+
+// runAdmin 执行 admin 子命令，用于创建第一个管理员：用户已存在时把角色改为管理员，
+// 否则从标准输入读取密码创建用户。密码不通过参数传入，避免出现在进程列表和 shell 历史中
+func runAdmin(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New(adminUsage)
+	}
+
+	authService := auth_service.Auth{Service: auth_service.NewService(models.NewRepositories().Users), Username: args[0]}
+	exists, err := authService.ExistByUsername()
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		fmt.Fprint(os.Stderr, "password: ")
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			return fmt.Errorf("read password: %v", err)
+		}
+		authService.Password = strings.TrimRight(password, "\r\n")
+		if len(authService.Password) < 6 {
+			return errors.New("password must be at least 6 characters")
+		}
+		if err := authService.Register(); err != nil {
+			return err
+		}
+	}
+
+	user, err := authService.Get()
+	if err != nil {
+		return err
+	}
+	authService.ID = user.ID
+	authService.Role = models.ROLE_ADMIN
+	if err := authService.SetRole(); err != nil {
+		return err
+	}
+
+	if exists {
+		fmt.Printf("user %s is now an admin\n", user.Username)
+	} else {
+		fmt.Printf("created admin %s\n", user.Username)
+	}
+	return nil
+}
@@ -1,35 +1,98 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/graceful"
 	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
 	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/metrics"
 	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 	"github.com/3Eeeecho/go-gin-example/routers"
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
 	"github.com/robfig/cron/v3"
 )
 
 func main() {
-	setting.SetUp()
+	configPath := flag.String("config", "", "配置文件路径，默认读取环境变量 GINBLOG_CONFIG，未设置时为 "+setting.DefaultConfigPath)
+	printConfig := flag.Bool("print-config", false, "打印合并默认值、配置文件和环境变量后的有效配置（隐藏敏感项）并退出")
+	flag.Parse()
+
+	if *printConfig {
+		cfg, err := setting.Load(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		cfg.Print(os.Stdout)
+		return
+	}
+
+	if err := setting.SetUp(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	logging.SetUp()
 	models.SetUp()
-	gredis.SetUp()
-	defer models.CloseDB()
 
-	router := routers.InitRouter()
+	if flag.Arg(0) == "migrate" {
+		exitCommand(runMigrate(flag.Args()[1:]))
+		return
+	}
+
+	if setting.DatabaseSetting.AutoMigrate {
+		applied, err := models.MigrateUp()
+		if err != nil {
+			logging.Fatal("Run models.MigrateUp failed:", err)
+			os.Exit(1)
+		}
+		if len(applied) > 0 {
+			logging.Info("Applied migrations:", strings.Join(applied, ", "))
+		}
+	}
+
+	// admin 需要在自动迁移之后执行，新数据库中还没有用户表
+	if flag.Arg(0) == "admin" {
+		exitCommand(runAdmin(flag.Args()[1:]))
+		return
+	}
+	if err := gredis.SetUp(); err != nil {
+		logging.Error("Connect to redis failed:", err)
+	}
+	services := service.New(models.NewRepositories())
+	router := routers.InitRouter(services)
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go setting.Watch(watchCtx, configWatchInterval, logReload)
 
 	c := cron.New()
-	c.AddFunc("@weekly", func() {
+	addJob(c, "@weekly", "models.CleanAllTag", func() error {
 		logging.Info("Run models.CleanAllTag...")
-		models.CleanAllTag()
+		_, err := models.CleanAllTag()
+		return err
 	})
-	c.AddFunc("@weekly", func() {
+	addJob(c, "@weekly", "models.CleanAllArticle", func() error {
 		logging.Info("Run models.CleanAllArticle...")
-		models.CleanAllArticle()
+		return models.CleanAllArticle()
+	})
+	addJob(c, "@weekly", "models.CleanAllComment", func() error {
+		logging.Info("Run models.CleanAllComment...")
+		return models.CleanAllComment()
 	})
+	addJob(c, "@every 1m", "article_service.FlushViews", services.Articles.FlushViews)
+	addJob(c, "@every 1m", "article_service.PublishScheduled", services.Articles.PublishScheduled)
 	c.Start()
 
 	s := &http.Server{
@@ -40,8 +103,134 @@ func main() {
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		logging.Fatal(fmt.Sprintf("Failed to start server: %v", err))
+	ln, err := graceful.Listen(s.Addr)
+	if err != nil {
+		logging.Fatal(fmt.Sprintf("Failed to listen on %s: %v", s.Addr, err))
+		shutdown(s, c, services.Articles)
+		os.Exit(1)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := s.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logging.Fatal(fmt.Sprintf("Failed to start server: %v", err))
+			serveErr <- err
+		}
+	}()
+	if err := graceful.Ready(); err != nil {
+		logging.Warn("Notify parent process failed:", err)
+	}
+
+	// 服务异常退出时同样先清理定时任务、Redis 和数据库，再以状态码 1 退出
+	err = waitForShutdown(ln, serveErr)
+	shutdown(s, c, services.Articles)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// exitCommand 子命令执行结束后关闭数据库和日志，出错时以状态码 1 退出
+func exitCommand(err error) {
+	models.CloseDB()
+	logging.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// 检查配置文件是否修改的间隔
+const configWatchInterval = 3 * time.Second
+
+// logReload 记录配置热更新的结果
+func logReload(changed []string, err error) {
+	if err != nil {
+		logging.Error("Reload config failed:", err)
+		return
+	}
+	if len(changed) > 0 {
+		logging.Info("Config reloaded, changed:", strings.Join(changed, ", "))
+	}
+}
+
+// addJob 注册定时任务，记录执行次数、失败次数和耗时，失败时写日志
+func addJob(c *cron.Cron, spec, name string, job func() error) {
+	run := metrics.CronJob(name, job)
+	c.AddFunc(spec, func() {
+		if err := run(); err != nil {
+			logging.Error("Run "+name+" failed:", err)
+		}
+	})
+}
+
+// waitForShutdown 阻塞直到收到退出信号、服务异常退出，或者平滑重启时新进程已经接管了监听 socket，
+// 服务异常退出时返回其错误。期间收到 SIGHUP 时重新加载配置
+func waitForShutdown(ln net.Listener, serveErr <-chan error) error {
+	signals := []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+	if graceful.RestartSignal != nil {
+		signals = append(signals, graceful.RestartSignal)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	defer signal.Stop(quit)
+
+	for {
+		var sig os.Signal
+		select {
+		case sig = <-quit:
+		case err := <-serveErr:
+			return err
+		}
+
+		if sig == syscall.SIGHUP {
+			logReload(setting.Reload())
+			continue
+		}
+
+		if sig != graceful.RestartSignal {
+			logging.Info("Received signal", sig, ", shutting down...")
+			return nil
+		}
+
+		if !setting.ServerSetting.GracefulRestart {
+			logging.Warn("Received", sig, "but GracefulRestart is disabled")
+			continue
+		}
+		if err := graceful.Restart(ln, setting.ServerSetting.ShutdownTimeout); err != nil {
+			logging.Error("Graceful restart failed:", err)
+			continue
+		}
+		logging.Info("New process is ready, shutting down...")
+		return nil
+	}
+}
+
+// shutdown 在 ShutdownTimeout 内依次停止接收请求并等待处理中的请求、停止定时任务并等待运行中的任务，
+// 最后通过 articles 写回浏览量并关闭 Redis、数据库和日志
+func shutdown(s *http.Server, c *cron.Cron, articles *article_service.Service) {
+	ctx, cancel := context.WithTimeout(context.Background(), setting.ServerSetting.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		logging.Error("Shutdown http server failed:", err)
+	}
+
+	select {
+	case <-c.Stop().Done():
+	case <-ctx.Done():
+		logging.Warn("Timed out waiting for cron jobs")
+	}
+
+	if err := articles.FlushViews(); err != nil {
+		logging.Error("Run article_service.FlushViews failed:", err)
+	}
+
+	if err := gredis.Close(); err != nil {
+		logging.Error("Close redis failed:", err)
 	}
+	models.CloseDB()
 
+	logging.Info("Server exited")
+	logging.Close()
 }
@@ -0,0 +1,49 @@
+package accesslog
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog 请求结束后记录一条访问日志，替代 gin.Logger。
+// 5xx 记为 ERROR，4xx 记为 WARN，其余为 INFO
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		// request_id、route 和 user 由 requestid 与 jwt 中间件加到了日志字段中
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", size),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "access", attrs...)
+	}
+}
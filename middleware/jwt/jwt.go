@@ -5,8 +5,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
 	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
 	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -33,15 +36,23 @@ func JWT() gin.HandlerFunc {
 				code = e.ERROR_AUTH_CHECK_TOKEN_FAIL
 			} else if time.Now().Unix() > claims.ExpiresAt {
 				code = e.ERROR_AUTH_CHECK_TOKEN_TIMEOUT
+			} else if claims.TokenType != util.TOKEN_TYPE_ACCESS {
+				code = e.ERROR_AUTH_CHECK_TOKEN_FAIL
+			} else if revoked, err := auth_service.IsTokenRevoked(claims); err != nil {
+				logging.Warn("check token revoked failed:", err)
+				code = e.ERROR_AUTH_CHECK_TOKEN_FAIL
+			} else if revoked {
+				code = e.ERROR_AUTH_TOKEN_REVOKED
+			} else {
+				c.Set(app.CLAIMS_KEY, claims)
+				ctx := logging.NewContext(c.Request.Context(), logging.KeyUser, claims.Username)
+				c.Request = c.Request.WithContext(ctx)
 			}
 		}
 
 		if code != e.SUCCESS {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": code,
-				"msg":  e.GetMsg(code),
-				"data": data,
-			})
+			g := app.Gin{C: c}
+			g.Response(http.StatusUnauthorized, code, data)
 			c.Abort()
 			return
 		}
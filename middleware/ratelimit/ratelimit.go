@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/ratelimit"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc 返回限流维度的值
+type KeyFunc func(c *gin.Context) string
+
+// 可在配置中选择的限流维度
+var keyFuncs = map[string]KeyFunc{
+	"ip":    ByIP,
+	"user":  ByUser,
+	"token": ByToken,
+}
+
+// ByIP 按客户端IP限流
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser 按登录用户限流，未登录时按IP
+func ByUser(c *gin.Context) string {
+	if claims, ok := app.GetClaims(c); ok && claims.UserID > 0 {
+		return "user:" + strconv.Itoa(claims.UserID)
+	}
+	return ByIP(c)
+}
+
+// ByToken 按 access token 限流，同一用户的多个会话分别计数，未登录时按IP
+func ByToken(c *gin.Context) string {
+	if claims, ok := app.GetClaims(c); ok && claims.Id != "" {
+		return "token:" + claims.Id
+	}
+	return ByIP(c)
+}
+
+// rule 解析后的分组限流规则
+type rule struct {
+	enabled bool
+	rate    setting.Rate
+	key     KeyFunc
+}
+
+func newRule(cfg *setting.RateLimit, group string) *rule {
+	rateRule, keyName := cfg.Rule(group)
+	// 配置加载时已经校验过规则
+	rate, _ := setting.ParseRate(rateRule)
+	key, ok := keyFuncs[keyName]
+	if !ok {
+		key = ByIP
+	}
+	return &rule{enabled: cfg.Enabled && rate.Limit > 0, rate: rate, key: key}
+}
+
+// RateLimit 按配置中 group 分组的规则和维度限流，超出后返回 429 和 Retry-After。
+// 规则随配置热更新；Redis 不可用时放行，避免限流拖垮整个服务。
+// 按 user 或 token 限流时需要放在 JWT 中间件之后
+func RateLimit(group string) gin.HandlerFunc {
+	var current atomic.Pointer[rule]
+	current.Store(newRule(&setting.Current().RateLimit, group))
+	setting.Subscribe(func(_, cfg *setting.Config) {
+		current.Store(newRule(&cfg.RateLimit, group))
+	})
+
+	return func(c *gin.Context) {
+		r := current.Load()
+		if !r.enabled {
+			c.Next()
+			return
+		}
+
+		cache := cache_service.RateLimit{Group: group, Key: r.key(c)}
+		result, err := ratelimit.Allow(c.Request.Context(), cache.GetKey(), r.rate)
+		if err != nil {
+			logging.Warn("rate limit failed:", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(r.rate.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			g := app.Gin{C: c}
+			g.ResponseRetryAfter(http.StatusTooManyRequests, e.ERROR_TOO_MANY_REQUESTS, result.RetryAfter)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package rbac
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+)
+
+// Require 只允许指定角色访问，必须注册在 jwt.JWT() 之后
+func Require(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := app.GetClaims(c)
+		if !ok {
+			abort(c, http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL)
+			return
+		}
+
+		if !hasRole(claims.Role, roles) {
+			abort(c, http.StatusForbidden, e.ERROR_AUTH_PERMISSION_DENIED)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ArticleOwner 只允许文章创建人或指定角色操作路径参数 id 对应的文章，articles 用于读取文章的创建人
+func ArticleOwner(articles *article_service.Service, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := app.GetClaims(c)
+		if !ok {
+			abort(c, http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL)
+			return
+		}
+
+		if hasRole(claims.Role, roles) {
+			c.Next()
+			return
+		}
+
+		articleService := article_service.Article{Service: articles, ID: com.StrTo(c.Param("id")).MustInt()}
+		article, err := articleService.Get()
+		if err != nil {
+			abort(c, http.StatusInternalServerError, e.ERROR_GET_ARTICLE_FAIL)
+			return
+		}
+
+		// 文章不存在时交给后续处理函数返回对应的错误
+		if article.ID > 0 && article.CreatedBy != claims.UserID {
+			abort(c, http.StatusForbidden, e.ERROR_AUTH_PERMISSION_DENIED)
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasRole(role string, roles []string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func abort(c *gin.Context, httpCode, errCode int) {
+	g := app.Gin{C: c}
+	g.Response(httpCode, errCode, nil)
+	c.Abort()
+}
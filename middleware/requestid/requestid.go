@@ -0,0 +1,58 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// HEADER 传递请求ID的请求头和响应头
+const HEADER = "X-Request-ID"
+
+// 客户端传入的请求ID最大长度，超过或含有非法字符时重新生成
+const maxLength = 64
+
+// RequestID 沿用客户端或上游代理传入的 X-Request-ID，没有时生成一个，
+// 写入响应头，并与路由模板一起加到请求的日志字段中
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HEADER)
+		if !valid(id) {
+			id = generate()
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		c.Set(app.REQUEST_ID_KEY, id)
+		c.Header(HEADER, id)
+		ctx := logging.NewContext(c.Request.Context(), logging.KeyRequestID, id, logging.KeyRoute, route)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func generate() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
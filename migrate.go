@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+)
+
+const migrateUsage = "usage: ginblog [-config path] migrate up|down [n]|status"
+
+// runMigrate 执行 migrate 子命令：up 执行全部未执行的迁移，down 回滚最近的 n 个迁移（默认 1 个），status 列出迁移状态
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := models.MigrateUp()
+		for _, id := range applied {
+			fmt.Println("applied", id)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step count %q\n%s", args[1], migrateUsage)
+			}
+			steps = n
+		}
+		reverted, err := models.MigrateDown(steps)
+		for _, id := range reverted {
+			fmt.Println("reverted", id)
+		}
+		return err
+
+	case "status":
+		statuses, err := models.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			if s.Applied {
+				fmt.Fprintf(w, "%s\tapplied\t%s\n", s.ID, time.Unix(int64(s.AppliedOn), 0).Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Fprintf(w, "%s\tpending\t\n", s.ID)
+			}
+		}
+		return w.Flush()
+	}
+
+	return errors.New(migrateUsage)
+}
@@ -5,16 +5,61 @@ import "github.com/jinzhu/gorm"
 type Article struct {
 	Model
 
-	TagID int `json:"tag_id" gorm:"index"`
-	Tag   Tag `json:"tag"`
+	Tags []Tag `json:"tags" gorm:"many2many:article_tag;"`
 
-	Title      string
-	Desc       string `json:"desc"`
-	Content    string `json:"content"`
-	CreatedBy  int    `json:"created_by"`
-	ModifiedBy int    `json:"modified_by"`
-	State      int    `json:"state"`
-	Views      int    `json:"views"`
+	CategoryID int      `json:"category_id" gorm:"index"`
+	Category   Category `json:"category"`
+
+	Title         string
+	Slug          string `json:"slug" gorm:"unique_index"`
+	Desc          string `json:"desc"`
+	Content       string `json:"content"`
+	CoverImageUrl string `json:"cover_image_url"`
+	CreatedBy     int    `json:"created_by"`
+	ModifiedBy    int    `json:"modified_by"`
+	State         int    `json:"state"`
+	Views         int    `json:"views"`
+
+	PublishAt   int `json:"publish_at" gorm:"index"` // 定时发布时间，0 表示未设置定时发布
+	ScheduledBy int `json:"scheduled_by"`            // 设置定时发布的用户ID
+}
+
+// ArticleFilter 文章列表中无法用 maps 表达的过滤条件
+type ArticleFilter struct {
+	// TagIDs 按标签过滤，MatchAllTags 为 true 时要求文章包含全部标签，否则包含任一标签即可
+	TagIDs       []int
+	MatchAllTags bool
+	// CategoryIDs 文章所属分类需在其中
+	CategoryIDs []int
+}
+
+func (f *ArticleFilter) apply(scope *gorm.DB) *gorm.DB {
+	if f == nil {
+		return scope
+	}
+
+	if len(f.TagIDs) > 0 {
+		sub := db.Model(&ArticleTag{}).Select("article_id").Where("tag_id IN (?)", f.TagIDs)
+		if f.MatchAllTags {
+			sub = sub.Group("article_id").Having("COUNT(DISTINCT tag_id) = ?", len(uniqueInts(f.TagIDs)))
+		}
+		scope = scope.Where("id IN (?)", sub.QueryExpr())
+	}
+
+	if len(f.CategoryIDs) > 0 {
+		scope = scope.Where("category_id IN (?)", f.CategoryIDs)
+	}
+
+	return scope
+}
+
+// ArticleSearchResult 全文搜索的结果行
+type ArticleSearchResult struct {
+	ID      int
+	Title   string
+	Desc    string
+	Content string
+	Score   float64
 }
 
 func ExistArticleByID(id int) (bool, error) {
@@ -27,18 +72,40 @@ func ExistArticleByID(id int) (bool, error) {
 	return article.ID > 0, nil
 }
 
-func GetArticleTotal(maps interface{}) (int, error) {
+// ExistArticleBySlug 检查 slug 是否已被其他文章使用，excludeID 为需要排除的文章ID
+func ExistArticleBySlug(slug string, excludeID int) (bool, error) {
+	var article Article
+	err := db.Select("id").Where("slug = ? AND id != ?", slug, excludeID).First(&article).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return article.ID > 0, nil
+}
+
+// GetArticleIDBySlug 根据 slug 获取文章ID，不存在时返回 0
+func GetArticleIDBySlug(slug string) (int, error) {
+	var article Article
+	err := db.Select("id").Where("slug = ?", slug).First(&article).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+
+	return article.ID, nil
+}
+
+func GetArticleTotal(maps interface{}, filter *ArticleFilter) (int, error) {
 	var count int
-	if err := db.Model(&Article{}).Where(maps).Count(&count).Error; err != nil {
+	if err := filter.apply(db.Model(&Article{}).Where(maps)).Count(&count).Error; err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
 
-func GetArticles(pageNum int, pageSize int, maps interface{}) ([]*Article, error) {
+func GetArticles(pageNum int, pageSize int, maps interface{}, filter *ArticleFilter) ([]*Article, error) {
 	var articles []*Article
-	err := db.Preload("Tag").Where(maps).Offset(pageNum).Limit(pageSize).Find(&articles).Error
+	err := filter.apply(db.Preload("Tags").Preload("Category").Where(maps)).Offset(pageNum).Limit(pageSize).Find(&articles).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
@@ -54,35 +121,163 @@ func GetArticle(id int) (*Article, error) {
 		return nil, err
 	}
 
-	err = db.Model(&article).Related(&article.Tag).Error
+	err = db.Model(&article).Related(&article.Tags, "Tags").Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
+	if article.CategoryID > 0 {
+		err = db.Model(&article).Related(&article.Category).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+
 	return &article, nil
 }
 
-func UpdateArticle(id int, data interface{}) error {
-	if err := db.Model(&Article{}).Where("id = ?", id).Updates(data).Error; err != nil {
+// GetAllArticles 获取全部文章（不含标签），用于构建搜索索引
+func GetAllArticles() ([]*Article, error) {
+	var articles []*Article
+	err := db.Find(&articles).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return articles, nil
+}
+
+// SearchArticles 搜索文章标题和内容，按相关度降序返回。
+// MySQL 使用 FULLTEXT 索引，PostgreSQL 使用 tsvector，SQLite 使用 LIKE
+func SearchArticles(query string, state int, pageNum int, pageSize int) ([]*ArticleSearchResult, int, error) {
+	var (
+		results []*ArticleSearchResult
+		count   int
+	)
+
+	cond, condArgs, score, scoreArgs := dia.match(query)
+	scope := db.Model(&Article{}).Where(cond, condArgs...)
+	if state >= 0 {
+		scope = scope.Where("state = ?", state)
+	}
+
+	if err := scope.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := scope.Select("id, title, "+quote("desc")+", content, "+score+" AS score", scoreArgs...).
+		Order("score DESC").Offset(pageNum).Limit(pageSize).Scan(&results).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, 0, err
+	}
+
+	return results, count, nil
+}
+
+// UpdateArticle 更新文章，并在同一事务中把修改后的内容保存为新版本，data["modified_by"] 为版本作者。
+// data["tag_ids"] 存在时在同一事务中替换文章的标签
+func UpdateArticle(id int, data map[string]interface{}) error {
+	return updateArticle(id, data, 0)
+}
+
+func updateArticle(id int, data map[string]interface{}, restoredFrom int) error {
+	modifiedBy, _ := data["modified_by"].(int)
+	tagIDs, replaceTags := data["tag_ids"].([]int)
+
+	columns := make(map[string]interface{}, len(data))
+	for name, value := range data {
+		if name != "tag_ids" {
+			columns[name] = value
+		}
+	}
+
+	tx := db.Begin()
+	if err := lockArticle(tx, id); err != nil {
+		tx.Rollback()
 		return err
 	}
-	return nil
+
+	if err := addInitialArticleRevision(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&Article{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if replaceTags {
+		if err := replaceArticleTags(tx, id, tagIDs); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := addArticleRevision(tx, id, modifiedBy, restoredFrom); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
-func AddArticle(data map[string]interface{}) error {
-	article := Article{
-		TagID:     data["tag_id"].(int),
-		Title:     data["title"].(string),
-		Desc:      data["desc"].(string),
-		Content:   data["content"].(string),
-		CreatedBy: data["created_by"].(int),
-		State:     data["state"].(int),
-		Views:     0,
-	}
-	if err := db.Create(&article).Error; err != nil {
+// addInitialArticleRevision 版本功能上线前创建的文章还没有任何版本，修改前先把当前内容保存为第一个版本
+func addInitialArticleRevision(tx *gorm.DB, id int) error {
+	var count int
+	if err := tx.Model(&ArticleRevision{}).Where("article_id = ?", id).Count(&count).Error; err != nil {
 		return err
 	}
-	return nil
+	if count > 0 {
+		return nil
+	}
+	return addArticleRevision(tx, id, 0, 0)
+}
+
+// lockArticle 在事务中锁定文章行，同一文章的修改排队执行，避免并发修改计算出相同的版本号
+func lockArticle(tx *gorm.DB, id int) error {
+	var article Article
+	return tx.Set("gorm:query_option", dia.forUpdate()).Select("id").Where("id = ?", id).First(&article).Error
+}
+
+// AddArticle 新增文章，返回新文章的ID
+func AddArticle(data map[string]interface{}) (int, error) {
+	article := Article{
+		Title:      data["title"].(string),
+		Slug:       data["slug"].(string),
+		CategoryID: data["category_id"].(int),
+		Desc:       data["desc"].(string),
+		Content:    data["content"].(string),
+		CreatedBy:  data["created_by"].(int),
+		State:      data["state"].(int),
+		Views:      0,
+	}
+	article.CoverImageUrl, _ = data["cover_image_url"].(string)
+	if publishAt, ok := data["publish_at"].(int); ok && publishAt > 0 {
+		article.PublishAt = publishAt
+		article.ScheduledBy, _ = data["scheduled_by"].(int)
+	}
+
+	tx := db.Begin()
+	if err := tx.Create(&article).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := replaceArticleTags(tx, article.ID, data["tag_ids"].([]int)); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := addArticleRevision(tx, article.ID, article.CreatedBy, 0); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return article.ID, nil
 }
 
 func DeleteArticle(id int) error {
@@ -93,10 +288,83 @@ func DeleteArticle(id int) error {
 	return nil
 }
 
+// AddArticleViews 批量累加文章浏览量，views 为文章ID到新增浏览量的映射
+func AddArticleViews(views map[int]int) error {
+	tx := db.Begin()
+	for id, n := range views {
+		err := tx.Model(&Article{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("COALESCE(views, 0) + ?", n)).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// PublishDueArticles 将定时发布时间不晚于 now 的草稿置为已发布并清除定时设置，返回被发布的文章ID。
+// 发布在同一事务中保存为新版本，版本作者为设置定时发布的用户
+func PublishDueArticles(now int64) ([]int, error) {
+	tx := db.Begin()
+
+	// 锁定到期的文章，避免与手动修改或其他实例的定时任务同时发布
+	var due []Article
+	err := tx.Set("gorm:query_option", dia.forUpdate()).Select("id, scheduled_by").
+		Where("state = ? AND publish_at > ? AND publish_at <= ?", 0, 0, now).Find(&due).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(due))
+	for _, article := range due {
+		if err := addInitialArticleRevision(tx, article.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err := tx.Model(&Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+			"state":        1,
+			"modified_by":  article.ScheduledBy,
+			"publish_at":   0,
+			"scheduled_by": 0,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := addArticleRevision(tx, article.ID, article.ScheduledBy, 0); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, article.ID)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, nil
+}
+
+// CleanAllArticle 彻底删除已软删除的文章及其标签关联。
+// 使用子查询而不是多表 DELETE，以兼容所有支持的数据库
 func CleanAllArticle() error {
-	if err := db.Unscoped().Where("deleted_on != ? ", 0).Delete(&Article{}).Error; err != nil {
+	deleted := db.Unscoped().Model(&Article{}).Select("id").Where("deleted_on != ?", 0).QueryExpr()
+
+	tx := db.Begin()
+	if err := tx.Where("article_id IN (?)", deleted).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	return nil
+	if err := tx.Unscoped().Where("deleted_on != ? ", 0).Delete(&Article{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
@@ -0,0 +1,101 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// ArticleRevision 文章的历史版本，每次修改文章都会保存一份修改后的完整快照
+type ArticleRevision struct {
+	Model
+
+	ArticleID    int    `json:"article_id" gorm:"unique_index:idx_article_revision"`
+	Revision     int    `json:"revision" gorm:"unique_index:idx_article_revision"` // 文章内从 1 开始递增的版本号
+	Title        string `json:"title"`
+	Desc         string `json:"desc"`
+	Content      string `json:"content,omitempty"`
+	CreatedBy    int    `json:"created_by"`    // 产生该版本的用户ID
+	RestoredFrom int    `json:"restored_from"` // 由哪个版本恢复而来，0 表示普通修改
+}
+
+func ExistArticleRevision(articleID int, revision int) (bool, error) {
+	var rev ArticleRevision
+	err := db.Select("id").Where("article_id = ? AND revision = ?", articleID, revision).First(&rev).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return rev.ID > 0, nil
+}
+
+func GetArticleRevisionTotal(articleID int) (int, error) {
+	var count int
+	if err := db.Model(&ArticleRevision{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetArticleRevisions 分页获取文章的版本列表，按版本号倒序，不包含正文
+func GetArticleRevisions(articleID int, pageNum int, pageSize int) ([]*ArticleRevision, error) {
+	var revisions []*ArticleRevision
+	err := db.Select("id, created_on, modified_on, article_id, revision, title, "+quote("desc")+", created_by, restored_from").
+		Where("article_id = ?", articleID).Order("revision desc").
+		Offset(pageNum).Limit(pageSize).Find(&revisions).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func GetArticleRevision(articleID int, revision int) (*ArticleRevision, error) {
+	var rev ArticleRevision
+	err := db.Where("article_id = ? AND revision = ?", articleID, revision).First(&rev).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// addArticleRevision 在事务中保存文章当前内容的快照，版本号为该文章已有的最大版本号加一。
+// createdBy 为 0 时以文章最后的修改人（没有则为创建人）作为版本作者
+func addArticleRevision(tx *gorm.DB, articleID int, createdBy int, restoredFrom int) error {
+	var article Article
+	err := tx.Select("id, title, "+quote("desc")+", content, created_by, modified_by").Where("id = ?", articleID).First(&article).Error
+	if err != nil {
+		return err
+	}
+
+	if createdBy == 0 {
+		createdBy = article.ModifiedBy
+	}
+	if createdBy == 0 {
+		createdBy = article.CreatedBy
+	}
+
+	var last ArticleRevision
+	err = tx.Select("revision").Where("article_id = ?", articleID).Order("revision desc").First(&last).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	return tx.Create(&ArticleRevision{
+		ArticleID:    articleID,
+		Revision:     last.Revision + 1,
+		Title:        article.Title,
+		Desc:         article.Desc,
+		Content:      article.Content,
+		CreatedBy:    createdBy,
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+// RestoreArticleRevision 用指定版本的内容覆盖文章，并保存为一个新的版本
+func RestoreArticleRevision(articleID int, revision *ArticleRevision, modifiedBy int) error {
+	return updateArticle(articleID, map[string]interface{}{
+		"title":       revision.Title,
+		"desc":        revision.Desc,
+		"content":     revision.Content,
+		"modified_by": modifiedBy,
+	}, revision.Revision)
+}
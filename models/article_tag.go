@@ -0,0 +1,45 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// ArticleTag 文章与标签的多对多关联
+type ArticleTag struct {
+	ArticleID int `gorm:"primary_key;auto_increment:false"`
+	TagID     int `gorm:"primary_key;auto_increment:false;index"`
+}
+
+// replaceArticleTags 在事务中将文章的标签替换为 tagIDs
+func replaceArticleTags(tx *gorm.DB, articleID int, tagIDs []int) error {
+	if err := tx.Where("article_id = ?", articleID).Delete(ArticleTag{}).Error; err != nil {
+		return err
+	}
+
+	for _, tagID := range uniqueInts(tagIDs) {
+		if err := tx.Create(&ArticleTag{ArticleID: articleID, TagID: tagID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetArticleIDsByTag 获取带有指定标签的文章ID
+func GetArticleIDsByTag(tagID int) ([]int, error) {
+	var ids []int
+	if err := db.Model(&ArticleTag{}).Where("tag_id = ?", tagID).Pluck("article_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -1,18 +1,111 @@
 package models
 
-import "github.com/jinzhu/gorm"
+import (
+	"sync/atomic"
+
+	"github.com/jinzhu/gorm"
+)
+
+// 用户角色
+const (
+	ROLE_ADMIN  = "admin"  // 管理员，拥有全部权限
+	ROLE_EDITOR = "editor" // 编辑，可以管理所有文章、标签和评论，审核评论除外
+	ROLE_AUTHOR = "author" // 作者，只能管理自己的文章
+	ROLE_READER = "reader" // 读者，只能浏览和评论
+)
 
 type User struct {
 	ID       int    `gorm:"primary_key" json:"id"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"-"`
+	Role     string `json:"role"`
+}
+
+// TableName 用户表沿用 auth 的表名
+func (User) TableName() string {
+	return table("auth")
+}
+
+// IsValidRole 判断角色是否合法
+func IsValidRole(role string) bool {
+	switch role {
+	case ROLE_ADMIN, ROLE_EDITOR, ROLE_AUTHOR, ROLE_READER:
+		return true
+	}
+	return false
+}
+
+func ExistUserByID(id int) (bool, error) {
+	var user User
+	err := db.Select("id").Where("id = ?", id).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+	return user.ID > 0, nil
+}
+
+func GetUserByUsername(username string) (*User, error) {
+	var user User
+	err := db.Where("username = ?", username).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func CheckUser(username, password string) (bool, error) {
+func ExistUserByUsername(username string) (bool, error) {
 	var user User
-	err := db.Select("id").Where(User{Username: username, Password: password}).First(&user).Error
+	err := db.Select("id").Where("username = ?", username).First(&user).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return false, err
 	}
 	return user.ID > 0, nil
 }
+
+// AddUser 新增用户，password 必须是已经哈希过的密码
+func AddUser(username, password, role string) error {
+	err := db.Create(&User{
+		Username: username,
+		Password: password,
+		Role:     role,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateUserPassword 更新用户密码，password 必须是已经哈希过的密码
+func UpdateUserPassword(id int, password string) error {
+	if err := db.Model(&User{}).Where("id = ?", id).Update("password", password).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateUserRole(id int, role string) error {
+	if err := db.Model(&User{}).Where("id = ?", id).Update("role", role).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// passwordWidened 加宽 password 列的迁移已经执行，回滚该迁移也不会缩短列，因此确认后不再查询
+var passwordWidened atomic.Bool
+
+// CanStorePasswordHash 返回 password 列是否足够保存 bcrypt 哈希。旧版用户表的 password 只有 VARCHAR(50)，
+// 严格模式下写入 60 个字符的哈希会失败，非严格模式下会被截断导致用户无法再登录
+func CanStorePasswordHash() (bool, error) {
+	if passwordWidened.Load() {
+		return true, nil
+	}
+
+	applied, err := MigrationApplied(MIGRATION_WIDEN_AUTH_PASSWORD)
+	if err != nil {
+		return false, err
+	}
+	if applied {
+		passwordWidened.Store(true)
+	}
+	return applied, nil
+}
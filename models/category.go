@@ -0,0 +1,204 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Category 多级分类，同时以邻接表（ParentID）和物化路径（Path）保存树结构
+type Category struct {
+	Model
+
+	Name       string `json:"name"`
+	ParentID   int    `json:"parent_id" gorm:"index"` // 父分类ID，0 表示顶级分类
+	Path       string `json:"path" gorm:"index"`      // 从根到自身的ID路径，例如 /1/4/9/
+	CreatedBy  string `json:"created_by"`
+	ModifiedBy string `json:"modified_by"`
+	State      int    `json:"state"`
+
+	Children []*Category `json:"children,omitempty" gorm:"-"`
+}
+
+// categoryPath 根据父分类路径生成分类路径
+func categoryPath(parentPath string, id int) string {
+	if parentPath == "" {
+		parentPath = "/"
+	}
+	return parentPath + strconv.Itoa(id) + "/"
+}
+
+func ExistCategoryByID(id int) (bool, error) {
+	var category Category
+	err := db.Select("id").Where("id = ?", id).First(&category).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return category.ID > 0, nil
+}
+
+// ExistCategoryByName 检查同一父分类下是否已存在同名分类，excludeID 为需要排除的分类ID
+func ExistCategoryByName(name string, parentID int, excludeID int) (bool, error) {
+	var category Category
+	err := db.Select("id").Where("name = ? AND parent_id = ? AND id != ?", name, parentID, excludeID).First(&category).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return category.ID > 0, nil
+}
+
+func GetCategory(id int) (*Category, error) {
+	var category Category
+	err := db.Where("id = ?", id).First(&category).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// GetCategories 获取分类，按路径排序以保证父分类在子分类之前
+func GetCategories(maps interface{}) ([]*Category, error) {
+	var categories []*Category
+	err := db.Where(maps).Order("path asc").Find(&categories).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetCategoryDescendantIDs 获取分类自身及其所有子孙分类的ID
+func GetCategoryDescendantIDs(id int) ([]int, error) {
+	category, err := GetCategory(id)
+	if err != nil {
+		return nil, err
+	}
+	if category.ID == 0 {
+		return nil, nil
+	}
+
+	var ids []int
+	err = db.Model(&Category{}).Where("path LIKE ?", category.Path+"%").Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetArticleIDsByCategories 获取属于指定分类的文章ID
+func GetArticleIDsByCategories(categoryIDs []int) ([]int, error) {
+	var ids []int
+	if len(categoryIDs) == 0 {
+		return ids, nil
+	}
+
+	if err := db.Model(&Article{}).Where("category_id IN (?)", categoryIDs).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func GetCategoryChildrenTotal(id int) (int, error) {
+	var count int
+	if err := db.Model(&Category{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// AddCategory 新增分类，parentPath 为父分类的路径，顶级分类传空字符串
+func AddCategory(name string, parentID int, parentPath string, state int, createdBy string) error {
+	category := Category{
+		Name:      name,
+		ParentID:  parentID,
+		State:     state,
+		CreatedBy: createdBy,
+	}
+
+	tx := db.Begin()
+	if err := tx.Create(&category).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	path := categoryPath(parentPath, category.ID)
+	if err := tx.Model(&category).UpdateColumn("path", path).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func EditCategory(id int, data interface{}) error {
+	if err := db.Model(&Category{}).Where("id = ?", id).Updates(data).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// MoveCategory 将分类移动到新的父分类下，并更新整棵子树的路径。
+// 调用方需要保证 parent 不是该分类本身或其子孙分类
+func MoveCategory(id int, parent *Category, modifiedBy string) error {
+	tx := db.Begin()
+
+	// 在事务中锁定分类后再读取子孙分类，避免同时进行的移动或新增使路径被旧值覆盖
+	var category Category
+	err := tx.Set("gorm:query_option", dia.forUpdate()).Where("id = ?", id).First(&category).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var descendants []*Category
+	err = tx.Where("path LIKE ? AND id != ?", category.Path+"%", id).Find(&descendants).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	newPath := categoryPath(parent.Path, id)
+
+	err = tx.Model(&Category{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"parent_id":   parent.ID,
+		"path":        newPath,
+		"modified_by": modifiedBy,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, d := range descendants {
+		path := newPath + strings.TrimPrefix(d.Path, category.Path)
+		if err := tx.Model(&Category{}).Where("id = ?", d.ID).UpdateColumn("path", path).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteCategory 删除分类，并将属于该分类的文章置为未分类
+func DeleteCategory(id int) error {
+	tx := db.Begin()
+	if err := tx.Where("id = ?", id).Delete(&Category{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&Article{}).Where("category_id = ?", id).UpdateColumn("category_id", 0).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,110 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// 评论审核状态
+const (
+	COMMENT_STATE_PENDING  = 0 // 待审核
+	COMMENT_STATE_APPROVED = 1 // 审核通过
+	COMMENT_STATE_REJECTED = 2 // 审核拒绝
+)
+
+type Comment struct {
+	Model
+
+	ArticleID int `json:"article_id" gorm:"index"`
+	ParentID  int `json:"parent_id" gorm:"index"` // 父评论ID，0 表示顶层评论
+
+	Content    string `json:"content"`
+	CreatedBy  string `json:"created_by"`
+	ModifiedBy string `json:"modified_by"`
+	State      int    `json:"state"`
+
+	Children []*Comment `json:"children" gorm:"-"`
+}
+
+func ExistCommentByID(id int) (bool, error) {
+	var comment Comment
+	err := db.Select("id").Where("id = ?", id).First(&comment).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return comment.ID > 0, nil
+}
+
+func GetCommentTotal(maps interface{}) (int, error) {
+	var count int
+	if err := db.Model(&Comment{}).Where(maps).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetComments 按条件获取评论，pageSize <= 0 时不分页
+func GetComments(pageNum int, pageSize int, maps interface{}) ([]*Comment, error) {
+	var (
+		comments []*Comment
+		err      error
+	)
+
+	query := db.Where(maps).Order("id asc")
+	if pageSize > 0 {
+		query = query.Offset(pageNum).Limit(pageSize)
+	}
+
+	err = query.Find(&comments).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func GetComment(id int) (*Comment, error) {
+	var comment Comment
+	err := db.Where("id = ?", id).First(&comment).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func AddComment(data map[string]interface{}) error {
+	comment := Comment{
+		ArticleID: data["article_id"].(int),
+		ParentID:  data["parent_id"].(int),
+		Content:   data["content"].(string),
+		CreatedBy: data["created_by"].(string),
+		State:     COMMENT_STATE_PENDING,
+	}
+	if err := db.Create(&comment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func EditComment(id int, data interface{}) error {
+	if err := db.Model(&Comment{}).Where("id = ?", id).Updates(data).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteComments 批量删除评论，用于连同回复一起删除
+func DeleteComments(ids []int) error {
+	if err := db.Where("id IN (?)", ids).Delete(Comment{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func CleanAllComment() error {
+	if err := db.Unscoped().Where("deleted_on != ? ", 0).Delete(&Comment{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
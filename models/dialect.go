@@ -0,0 +1,250 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+// dialect 封装不同数据库之间有差异的连接串和 SQL，其余查询都通过 GORM 生成
+type dialect interface {
+	// dsn 根据配置生成连接串
+	dsn(cfg *setting.Database) string
+	// configure 打开连接后按数据库调整连接池等设置
+	configure(db *gorm.DB)
+
+	// primaryKey 自增主键 id 的列定义
+	primaryKey() string
+	// longText 长文本的列类型
+	longText() string
+	// tableOptions 追加在 CREATE TABLE 之后的建表选项
+	tableOptions() string
+	// concat 拼接字符串
+	concat(args ...string) string
+	// dropIndex 删除表上的索引
+	dropIndex(index, table string) string
+	// indexExists 检查表上是否已有该索引
+	indexExists(tx *gorm.DB, index, table string) (bool, error)
+	// columnExists 检查表上是否已有该列
+	columnExists(tx *gorm.DB, table, column string) (bool, error)
+	// forUpdate 追加在 SELECT 之后的行锁子句，不支持时为空
+	forUpdate() string
+	// modifyColumn 修改列的类型，constraints 为列上需要保留的 NOT NULL、DEFAULT 等约束，不需要修改时为空
+	modifyColumn(table, column, typ, constraints string) []string
+
+	// fullTextIndex 为文章标题和内容建立全文索引的 SQL，不支持时为空
+	fullTextIndex(table string) []string
+	// dropFullTextIndex 删除 fullTextIndex 建立的索引
+	dropFullTextIndex(table string) []string
+	// match 全文搜索的过滤条件和相关度表达式
+	match(query string) (cond string, condArgs []interface{}, score string, scoreArgs []interface{})
+}
+
+func newDialect(name string) (dialect, error) {
+	switch name {
+	case setting.DB_MYSQL:
+		return mysqlDialect{}, nil
+	case setting.DB_POSTGRES:
+		return postgresDialect{}, nil
+	case setting.DB_SQLITE:
+		return sqliteDialect{}, nil
+	}
+	return nil, fmt.Errorf("models: unsupported database type %q", name)
+}
+
+// quote 按当前数据库给列名加引号，用于 desc 这类保留字
+func quote(name string) string {
+	return db.Dialect().Quote(name)
+}
+
+// exists 执行 SELECT COUNT(*) 查询，返回结果是否大于 0
+func exists(tx *gorm.DB, sql string, args ...interface{}) (bool, error) {
+	var count int
+	if err := tx.Raw(sql, args...).Row().Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) dsn(cfg *setting.Database) string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		cfg.User, cfg.Password, cfg.Host, cfg.Name)
+}
+
+func (mysqlDialect) configure(db *gorm.DB) {}
+
+func (mysqlDialect) primaryKey() string {
+	return "id INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY"
+}
+
+func (mysqlDialect) longText() string { return "LONGTEXT" }
+
+func (mysqlDialect) tableOptions() string { return " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4" }
+
+func (mysqlDialect) concat(args ...string) string {
+	return "CONCAT(" + strings.Join(args, ", ") + ")"
+}
+
+func (mysqlDialect) dropIndex(index, table string) string {
+	return "DROP INDEX " + index + " ON " + table
+}
+
+func (mysqlDialect) indexExists(tx *gorm.DB, index, table string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM information_schema.statistics "+
+		"WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?", table, index)
+}
+
+func (mysqlDialect) forUpdate() string { return "FOR UPDATE" }
+
+func (mysqlDialect) columnExists(tx *gorm.DB, table, column string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM information_schema.columns "+
+		"WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", table, column)
+}
+
+// modifyColumn MODIFY 会重写整个列定义，因此需要带上原有的约束
+func (mysqlDialect) modifyColumn(table, column, typ, constraints string) []string {
+	return []string{"ALTER TABLE " + table + " MODIFY COLUMN " + column + " " + typ + " " + constraints}
+}
+
+// fullTextIndex 使用 ngram 解析器，支持中文分词
+func (mysqlDialect) fullTextIndex(table string) []string {
+	return []string{"CREATE FULLTEXT INDEX ft_" + table + " ON " + table + " (title, content) WITH PARSER ngram"}
+}
+
+func (d mysqlDialect) dropFullTextIndex(table string) []string {
+	return []string{d.dropIndex("ft_"+table, table)}
+}
+
+func (mysqlDialect) match(query string) (string, []interface{}, string, []interface{}) {
+	expr := "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	return expr, []interface{}{query}, expr, []interface{}{query}
+}
+
+type postgresDialect struct{}
+
+// dsn 未配置 SSL，生产环境可以通过 PGSSLMODE 等环境变量覆盖
+func (postgresDialect) dsn(cfg *setting.Database) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     cfg.Host,
+		Path:     "/" + cfg.Name,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
+}
+
+func (postgresDialect) configure(db *gorm.DB) {}
+
+func (postgresDialect) primaryKey() string { return "id SERIAL PRIMARY KEY" }
+
+func (postgresDialect) longText() string { return "TEXT" }
+
+func (postgresDialect) tableOptions() string { return "" }
+
+func (postgresDialect) concat(args ...string) string {
+	return "CONCAT(" + strings.Join(args, ", ") + ")"
+}
+
+func (postgresDialect) dropIndex(index, table string) string {
+	return "DROP INDEX " + index
+}
+
+// indexExists PostgreSQL 的索引名在 schema 内唯一，不需要按表过滤
+func (postgresDialect) indexExists(tx *gorm.DB, index, table string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM pg_indexes WHERE schemaname = CURRENT_SCHEMA() AND indexname = ?", index)
+}
+
+func (postgresDialect) forUpdate() string { return "FOR UPDATE" }
+
+func (postgresDialect) columnExists(tx *gorm.DB, table, column string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM information_schema.columns "+
+		"WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?", table, column)
+}
+
+// modifyColumn 只修改类型，原有约束保持不变
+func (postgresDialect) modifyColumn(table, column, typ, constraints string) []string {
+	return []string{"ALTER TABLE " + table + " ALTER COLUMN " + column + " TYPE " + typ}
+}
+
+// 使用 simple 配置不做词干处理，中文按整段匹配，效果不如 MySQL 的 ngram
+const postgresDocument = "to_tsvector('simple', title || ' ' || COALESCE(content, ''))"
+
+func (postgresDialect) fullTextIndex(table string) []string {
+	return []string{"CREATE INDEX ft_" + table + " ON " + table + " USING GIN (" + postgresDocument + ")"}
+}
+
+func (d postgresDialect) dropFullTextIndex(table string) []string {
+	return []string{d.dropIndex("ft_"+table, table)}
+}
+
+func (postgresDialect) match(query string) (string, []interface{}, string, []interface{}) {
+	return postgresDocument + " @@ plainto_tsquery('simple', ?)", []interface{}{query},
+		"ts_rank(" + postgresDocument + ", plainto_tsquery('simple', ?))", []interface{}{query}
+}
+
+type sqliteDialect struct{}
+
+// dsn Name 为数据库文件路径，:memory: 为内存数据库
+func (sqliteDialect) dsn(cfg *setting.Database) string {
+	return cfg.Name
+}
+
+// configure SQLite 同一时间只允许一个写入者，内存数据库的每个连接又是独立的数据库，
+// 因此只使用一个连接
+func (sqliteDialect) configure(db *gorm.DB) {
+	db.DB().SetMaxOpenConns(1)
+}
+
+func (sqliteDialect) primaryKey() string { return "id INTEGER PRIMARY KEY AUTOINCREMENT" }
+
+func (sqliteDialect) longText() string { return "TEXT" }
+
+func (sqliteDialect) tableOptions() string { return "" }
+
+func (sqliteDialect) concat(args ...string) string {
+	return "(" + strings.Join(args, " || ") + ")"
+}
+
+func (sqliteDialect) dropIndex(index, table string) string {
+	return "DROP INDEX " + index
+}
+
+func (sqliteDialect) indexExists(tx *gorm.DB, index, table string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", index)
+}
+
+// forUpdate SQLite 只使用一个连接，写事务本身就是串行的
+func (sqliteDialect) forUpdate() string { return "" }
+
+func (sqliteDialect) columnExists(tx *gorm.DB, table, column string) (bool, error) {
+	return exists(tx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column)
+}
+
+// modifyColumn SQLite 不检查 VARCHAR 的长度，也不支持修改列，无需处理
+func (sqliteDialect) modifyColumn(table, column, typ, constraints string) []string { return nil }
+
+// fullTextIndex SQLite 使用 LIKE 搜索，不建立索引
+func (sqliteDialect) fullTextIndex(table string) []string { return nil }
+
+func (sqliteDialect) dropFullTextIndex(table string) []string { return nil }
+
+// match 标题命中的文章排在前面
+func (sqliteDialect) match(query string) (string, []interface{}, string, []interface{}) {
+	pattern := "%" + escapeLike(query) + "%"
+	return `(title LIKE ? ESCAPE '\' OR content LIKE ? ESCAPE '\')`, []interface{}{pattern, pattern},
+		`(CASE WHEN title LIKE ? ESCAPE '\' THEN 2 ELSE 1 END)`, []interface{}{pattern}
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
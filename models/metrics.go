@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/metrics"
+	"github.com/jinzhu/gorm"
+)
+
+const metricsStartKey = "metrics:start_time"
+
+// registerMetricsCallbacks 在每类操作的前后注册回调，记录 SQL 执行耗时
+func registerMetricsCallbacks(db *gorm.DB) {
+	callback := db.Callback()
+
+	callback.Create().Before("gorm:begin_transaction").Register("metrics:before_create", beforeQuery)
+	callback.Create().After("gorm:commit_or_rollback_transaction").Register("metrics:after_create", afterQuery("create"))
+	callback.Query().Before("gorm:query").Register("metrics:before_query", beforeQuery)
+	callback.Query().After("gorm:after_query").Register("metrics:after_query", afterQuery("query"))
+	callback.Update().Before("gorm:begin_transaction").Register("metrics:before_update", beforeQuery)
+	callback.Update().After("gorm:commit_or_rollback_transaction").Register("metrics:after_update", afterQuery("update"))
+	callback.Delete().Before("gorm:begin_transaction").Register("metrics:before_delete", beforeQuery)
+	callback.Delete().After("gorm:commit_or_rollback_transaction").Register("metrics:after_delete", afterQuery("delete"))
+	callback.RowQuery().Before("gorm:row_query").Register("metrics:before_row_query", beforeQuery)
+	callback.RowQuery().After("gorm:row_query").Register("metrics:after_row_query", afterQuery("row_query"))
+}
+
+func beforeQuery(scope *gorm.Scope) {
+	scope.Set(metricsStartKey, time.Now())
+}
+
+func afterQuery(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		v, ok := scope.Get(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		metrics.DBQueryDuration.WithLabelValues(operation, scope.TableName()).Observe(time.Since(start).Seconds())
+	}
+}
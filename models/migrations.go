@@ -0,0 +1,319 @@
+package models
+
+import (
+	"github.com/3Eeeecho/go-gin-example/pkg/migrate"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/jinzhu/gorm"
+)
+
+// MIGRATIONS_TABLE 迁移历史表，同样会加上表前缀
+const MIGRATIONS_TABLE = "migrations"
+
+// table 返回加上配置中表前缀的表名
+func table(name string) string {
+	return setting.DatabaseSetting.TablePrefix + name
+}
+
+// index 返回 tbl 表上 column 列的索引名。PostgreSQL 和 SQLite 的索引名在整个库内唯一，因此带上表名
+func index(tbl, column string) string {
+	return "idx_" + tbl + "_" + column
+}
+
+// execAll 依次执行多条 SQL，遇到错误时停止
+func execAll(tx *gorm.DB, sqls ...string) error {
+	for _, sql := range sqls {
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexIfNotExists 在 tbl 的 column 列上创建索引，同名索引已存在时跳过。
+// MySQL 不支持 CREATE INDEX IF NOT EXISTS，因此先查询索引是否存在
+func createIndexIfNotExists(tx *gorm.DB, tbl, column string, unique bool) error {
+	name := index(tbl, column)
+	exists, err := dia.indexExists(tx, name, tbl)
+	if err != nil || exists {
+		return err
+	}
+
+	sql := "CREATE INDEX "
+	if unique {
+		sql = "CREATE UNIQUE INDEX "
+	}
+	return tx.Exec(sql + name + " ON " + tbl + " (" + column + ")").Error
+}
+
+// addColumnIfNotExists 在 tbl 上新增列，同名列已存在时跳过，用于旧版手工建表时可能已有的列
+func addColumnIfNotExists(tx *gorm.DB, tbl, column, definition string) error {
+	exists, err := dia.columnExists(tx, tbl, column)
+	if err != nil || exists {
+		return err
+	}
+	return tx.Exec("ALTER TABLE " + tbl + " ADD COLUMN " + column + " " + definition).Error
+}
+
+// migrations 数据库结构的全部变更，已发布的迁移不能修改，结构变化时追加新的迁移。
+// 0001 的表和索引都只在不存在时创建，已经手工建好基础表的数据库也可以直接接入。
+// SQL 需要同时兼容 MySQL、PostgreSQL 和 SQLite，有差异的部分通过 dia 生成：
+// 每条 ALTER TABLE 只做一项修改，索引单独创建，删除列之前先删除列上的索引
+var migrations = []migrate.Migration{
+	{
+		ID: "0001_create_tag_article_auth",
+		Up: func(tx *gorm.DB) error {
+			err := execAll(tx,
+				"CREATE TABLE IF NOT EXISTS "+table("tag")+" ("+
+					dia.primaryKey()+", "+
+					"name VARCHAR(100) NOT NULL DEFAULT '', "+
+					"created_on INTEGER NOT NULL DEFAULT 0, "+
+					"created_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"modified_on INTEGER NOT NULL DEFAULT 0, "+
+					"modified_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"deleted_on INTEGER NOT NULL DEFAULT 0, "+
+					"state SMALLINT NOT NULL DEFAULT 1)"+dia.tableOptions(),
+				"CREATE TABLE IF NOT EXISTS "+table("article")+" ("+
+					dia.primaryKey()+", "+
+					"tag_id INTEGER NOT NULL DEFAULT 0, "+
+					"title VARCHAR(100) NOT NULL DEFAULT '', "+
+					quote("desc")+" VARCHAR(255) NOT NULL DEFAULT '', "+
+					"content "+dia.longText()+", "+
+					"created_on INTEGER NOT NULL DEFAULT 0, "+
+					"created_by INTEGER NOT NULL DEFAULT 0, "+
+					"modified_on INTEGER NOT NULL DEFAULT 0, "+
+					"modified_by INTEGER NOT NULL DEFAULT 0, "+
+					"deleted_on INTEGER NOT NULL DEFAULT 0, "+
+					"state SMALLINT NOT NULL DEFAULT 1, "+
+					"views INTEGER NOT NULL DEFAULT 0)"+dia.tableOptions(),
+				"CREATE TABLE IF NOT EXISTS "+table("auth")+" ("+
+					dia.primaryKey()+", "+
+					"username VARCHAR(50) NOT NULL DEFAULT '', "+
+					"password VARCHAR(100) NOT NULL DEFAULT '')"+dia.tableOptions(),
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := createIndexIfNotExists(tx, table("article"), "tag_id", false); err != nil {
+				return err
+			}
+			return createIndexIfNotExists(tx, table("auth"), "username", true)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"DROP TABLE IF EXISTS "+table("auth"),
+				"DROP TABLE IF EXISTS "+table("article"),
+				"DROP TABLE IF EXISTS "+table("tag"),
+			)
+		},
+	},
+	{
+		ID: "0002_create_comment",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"CREATE TABLE "+table("comment")+" ("+
+					dia.primaryKey()+", "+
+					"article_id INTEGER NOT NULL DEFAULT 0, "+
+					"parent_id INTEGER NOT NULL DEFAULT 0, "+
+					"content TEXT, "+
+					"created_on INTEGER NOT NULL DEFAULT 0, "+
+					"created_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"modified_on INTEGER NOT NULL DEFAULT 0, "+
+					"modified_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"deleted_on INTEGER NOT NULL DEFAULT 0, "+
+					"state SMALLINT NOT NULL DEFAULT 0)"+dia.tableOptions(),
+				"CREATE INDEX "+index(table("comment"), "article_id")+" ON "+table("comment")+" (article_id)",
+				"CREATE INDEX "+index(table("comment"), "parent_id")+" ON "+table("comment")+" (parent_id)",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx, "DROP TABLE IF EXISTS "+table("comment"))
+		},
+	},
+	{
+		ID: "0003_add_auth_role",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"ALTER TABLE "+table("auth")+" ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT '"+ROLE_READER+"'",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx, "ALTER TABLE "+table("auth")+" DROP COLUMN role")
+		},
+	},
+	{
+		ID: "0004_add_article_slug",
+		Up: func(tx *gorm.DB) error {
+			// 已有文章的 slug 使用 article-<id>，保证唯一索引可以建立
+			return execAll(tx,
+				"ALTER TABLE "+table("article")+" ADD COLUMN slug VARCHAR(191) NOT NULL DEFAULT ''",
+				"UPDATE "+table("article")+" SET slug = "+dia.concat("'article-'", "id")+" WHERE slug = ''",
+				"CREATE UNIQUE INDEX "+index(table("article"), "slug")+" ON "+table("article")+" (slug)",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				dia.dropIndex(index(table("article"), "slug"), table("article")),
+				"ALTER TABLE "+table("article")+" DROP COLUMN slug",
+			)
+		},
+	},
+	{
+		ID: "0005_add_article_fulltext",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx, dia.fullTextIndex(table("article"))...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx, dia.dropFullTextIndex(table("article"))...)
+		},
+	},
+	{
+		ID: "0006_create_article_tag",
+		Up: func(tx *gorm.DB) error {
+			// 把旧版文章表 tag_id 列中的数据迁移到关联表
+			return execAll(tx,
+				"CREATE TABLE "+table("article_tag")+" ("+
+					"article_id INTEGER NOT NULL, "+
+					"tag_id INTEGER NOT NULL, "+
+					"PRIMARY KEY (article_id, tag_id))"+dia.tableOptions(),
+				"CREATE INDEX "+index(table("article_tag"), "tag_id")+" ON "+table("article_tag")+" (tag_id)",
+				"INSERT INTO "+table("article_tag")+" (article_id, tag_id) "+
+					"SELECT id, tag_id FROM "+table("article")+" WHERE tag_id > 0",
+				dia.dropIndex(index(table("article"), "tag_id"), table("article")),
+				"ALTER TABLE "+table("article")+" DROP COLUMN tag_id",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 旧版每篇文章只有一个标签，保留ID最小的标签
+			return execAll(tx,
+				"ALTER TABLE "+table("article")+" ADD COLUMN tag_id INTEGER NOT NULL DEFAULT 0",
+				"CREATE INDEX "+index(table("article"), "tag_id")+" ON "+table("article")+" (tag_id)",
+				"UPDATE "+table("article")+" SET tag_id = COALESCE((SELECT MIN(t.tag_id) FROM "+table("article_tag")+
+					" t WHERE t.article_id = "+table("article")+".id), 0)",
+				"DROP TABLE IF EXISTS "+table("article_tag"),
+			)
+		},
+	},
+	{
+		ID: "0007_create_category",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"CREATE TABLE "+table("category")+" ("+
+					dia.primaryKey()+", "+
+					"name VARCHAR(100) NOT NULL DEFAULT '', "+
+					"parent_id INTEGER NOT NULL DEFAULT 0, "+
+					"path VARCHAR(191) NOT NULL DEFAULT '', "+
+					"created_on INTEGER NOT NULL DEFAULT 0, "+
+					"created_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"modified_on INTEGER NOT NULL DEFAULT 0, "+
+					"modified_by VARCHAR(100) NOT NULL DEFAULT '', "+
+					"deleted_on INTEGER NOT NULL DEFAULT 0, "+
+					"state SMALLINT NOT NULL DEFAULT 1)"+dia.tableOptions(),
+				"CREATE INDEX "+index(table("category"), "parent_id")+" ON "+table("category")+" (parent_id)",
+				"CREATE INDEX "+index(table("category"), "path")+" ON "+table("category")+" (path)",
+				"ALTER TABLE "+table("article")+" ADD COLUMN category_id INTEGER NOT NULL DEFAULT 0",
+				"CREATE INDEX "+index(table("article"), "category_id")+" ON "+table("article")+" (category_id)",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				dia.dropIndex(index(table("article"), "category_id"), table("article")),
+				"ALTER TABLE "+table("article")+" DROP COLUMN category_id",
+				"DROP TABLE IF EXISTS "+table("category"),
+			)
+		},
+	},
+	{
+		ID: "0008_create_article_revision",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"CREATE TABLE "+table("article_revision")+" ("+
+					dia.primaryKey()+", "+
+					"article_id INTEGER NOT NULL DEFAULT 0, "+
+					"revision INTEGER NOT NULL DEFAULT 0, "+
+					"title VARCHAR(100) NOT NULL DEFAULT '', "+
+					quote("desc")+" VARCHAR(255) NOT NULL DEFAULT '', "+
+					"content "+dia.longText()+", "+
+					"created_on INTEGER NOT NULL DEFAULT 0, "+
+					"created_by INTEGER NOT NULL DEFAULT 0, "+
+					"modified_on INTEGER NOT NULL DEFAULT 0, "+
+					"deleted_on INTEGER NOT NULL DEFAULT 0, "+
+					"restored_from INTEGER NOT NULL DEFAULT 0)"+dia.tableOptions(),
+				"CREATE UNIQUE INDEX "+index(table("article_revision"), "article_revision")+
+					" ON "+table("article_revision")+" (article_id, revision)",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx, "DROP TABLE IF EXISTS "+table("article_revision"))
+		},
+	},
+	{
+		ID: "0009_add_article_publish_at",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				"ALTER TABLE "+table("article")+" ADD COLUMN publish_at INTEGER NOT NULL DEFAULT 0",
+				"ALTER TABLE "+table("article")+" ADD COLUMN scheduled_by INTEGER NOT NULL DEFAULT 0",
+				"CREATE INDEX "+index(table("article"), "publish_at")+" ON "+table("article")+" (publish_at)",
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				dia.dropIndex(index(table("article"), "publish_at"), table("article")),
+				"ALTER TABLE "+table("article")+" DROP COLUMN scheduled_by",
+				"ALTER TABLE "+table("article")+" DROP COLUMN publish_at",
+			)
+		},
+	},
+	{
+		ID: MIGRATION_WIDEN_AUTH_PASSWORD,
+		Up: func(tx *gorm.DB) error {
+			// 旧版手工建的文章表可能已有 cover_image_url，旧版用户表的 password 只有 VARCHAR(50)，
+			// 0001 不会修改已存在的表，而 bcrypt 哈希固定为 60 个字符
+			err := addColumnIfNotExists(tx, table("article"), "cover_image_url", "VARCHAR(255) NOT NULL DEFAULT ''")
+			if err != nil {
+				return err
+			}
+			return execAll(tx, dia.modifyColumn(table("auth"), "password", "VARCHAR(100)", "NOT NULL DEFAULT ''")...)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 不缩短 password，已保存的哈希会被截断
+			return execAll(tx, "ALTER TABLE "+table("article")+" DROP COLUMN cover_image_url")
+		},
+	},
+}
+
+// MIGRATION_WIDEN_AUTH_PASSWORD 加宽用户表 password 列的迁移，执行之前不能保存 bcrypt 哈希
+const MIGRATION_WIDEN_AUTH_PASSWORD = "0010_add_article_cover_widen_auth_password"
+
+func newMigrator() *migrate.Migrator {
+	return migrate.New(db, table(MIGRATIONS_TABLE), migrations)
+}
+
+// MigrateUp 执行所有未执行的迁移，返回本次执行的迁移ID
+func MigrateUp() ([]string, error) {
+	return newMigrator().Up()
+}
+
+// MigrateDown 回滚最近的 steps 个迁移，返回本次回滚的迁移ID
+func MigrateDown(steps int) ([]string, error) {
+	return newMigrator().Down(steps)
+}
+
+// MigrationApplied 返回迁移是否已执行
+func MigrationApplied(id string) (bool, error) {
+	statuses, err := MigrationStatus()
+	if err != nil {
+		return false, err
+	}
+	for _, status := range statuses {
+		if status.ID == id {
+			return status.Applied, nil
+		}
+	}
+	return false, nil
+}
+
+// MigrationStatus 返回所有迁移的执行状态
+func MigrationStatus() ([]migrate.Status, error) {
+	return newMigrator().Status()
+}
@@ -1,13 +1,13 @@
 package models
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"time"
 
 	"github.com/3Eeeecho/go-gin-example/pkg/logging"
 	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 	"github.com/jinzhu/gorm"
-	_ "github.com/jinzhu/gorm/dialects/mysql"
 )
 
 // 定义全局变量 db，用于存储数据库连接
@@ -21,38 +21,36 @@ type Model struct {
 	DeletedOn  int `json:"delete_on" gorm:"softDelete"`
 }
 
+// 当前数据库的方言，在 SetUp 中根据 [database] Type 设置
+var dia dialect
+
 func SetUp() {
-	var (
-		err                                  error
-		dbType, dbName, user, password, host string
-	)
-
-	// 读取数据库配置
-	dbType = setting.DatabaseSetting.Type       // 数据库类型（如 mysql）
-	dbName = setting.DatabaseSetting.Name       // 数据库名称
-	user = setting.DatabaseSetting.User         // 数据库用户名
-	password = setting.DatabaseSetting.Password // 数据库密码
-	host = setting.DatabaseSetting.Host         // 数据库主机地址
+	var err error
+
+	// 根据数据库类型（mysql、postgres 或 sqlite3）生成连接串
+	dia, err = newDialect(setting.DatabaseSetting.Type)
+	if err != nil {
+		logging.Fatal(err)
+		return
+	}
 
 	// 使用 GORM 打开数据库连接
-	db, err = gorm.Open(dbType, fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local",
-		user,
-		password,
-		host,
-		dbName))
+	db, err = gorm.Open(setting.DatabaseSetting.Type, dia.dsn(setting.DatabaseSetting))
 
 	if err != nil {
 		logging.Info(err) // 如果连接失败，记录错误日志
 	}
 
 	// 设置 GORM 的表名处理函数
-	// 默认表名会加上配置中的表前缀
+	// 默认表名会加上配置中的表前缀，表名使用单数形式，与 migrations 中建的表一致
 	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
-		return defaultTableName
+		return setting.DatabaseSetting.TablePrefix + defaultTableName
 	}
+	db.SingularTable(true)
 
 	db.Callback().Create().Replace("gorm:update_time_stamp", updateTimeStampForCreateCallback)
 	db.Callback().Update().Replace("gorm:update_time_stamp", updateTimeStampForUpdateCallback)
+	registerMetricsCallbacks(db)
 
 	// 开启 GORM 的调试模式，打印所有执行的 SQL 语句
 	db.LogMode(true)
@@ -62,6 +60,16 @@ func SetUp() {
 
 	// 设置数据库连接池的最大打开连接数
 	db.DB().SetMaxOpenConns(100)
+
+	dia.configure(db)
+}
+
+// Ping 检查数据库连接是否可用
+func Ping(ctx context.Context) error {
+	if db == nil {
+		return errors.New("database is not connected")
+	}
+	return db.DB().PingContext(ctx)
 }
 
 // CloseDB 关闭数据库连接
@@ -0,0 +1,291 @@
+package models
+
+// ArticleRepository 文章及其标签关联、版本的存取接口，服务层通过它访问文章数据，
+// 方法的语义与同名的包级函数一致：记录不存在时返回 ID 为 0 的空记录而不是错误
+type ArticleRepository interface {
+	ExistByID(id int) (bool, error)
+	// ExistBySlug 检查 slug 是否已被其他文章使用，excludeID 为需要排除的文章ID
+	ExistBySlug(slug string, excludeID int) (bool, error)
+	GetIDBySlug(slug string) (int, error)
+
+	Get(id int) (*Article, error)
+	// GetAll 获取全部文章（不含标签），用于构建搜索索引
+	GetAll() ([]*Article, error)
+	// List 分页获取文章，maps 为等值查询条件
+	List(pageNum int, pageSize int, maps interface{}, filter *ArticleFilter) ([]*Article, error)
+	Count(maps interface{}, filter *ArticleFilter) (int, error)
+
+	// Add 新增文章并保存第一个版本，返回新文章的ID
+	Add(data map[string]interface{}) (int, error)
+	// Update 更新文章并保存为新版本，data["tag_ids"] 存在时同时替换文章的标签
+	Update(id int, data map[string]interface{}) error
+	Delete(id int) error
+	AddViews(views map[int]int) error
+	// PublishDue 发布定时发布时间不晚于 now 的草稿并清除定时设置，同时以设置定时发布的用户保存新版本，返回被发布的文章ID
+	PublishDue(now int64) ([]int, error)
+
+	GetRevisions(articleID int, pageNum int, pageSize int) ([]*ArticleRevision, error)
+	CountRevisions(articleID int) (int, error)
+	GetRevision(articleID int, revision int) (*ArticleRevision, error)
+	ExistRevision(articleID int, revision int) (bool, error)
+	RestoreRevision(articleID int, revision *ArticleRevision, modifiedBy int) error
+}
+
+// ArticleSearcher 使用数据库全文检索的文章搜索，只有数据库仓库实现，
+// 其他仓库由 search_service 的内存引擎建立索引
+type ArticleSearcher interface {
+	// Search 搜索标题和内容，按相关度降序返回结果和总数，state 小于 0 时不限状态
+	Search(query string, state int, pageNum int, pageSize int) ([]*ArticleSearchResult, int, error)
+}
+
+// TagRepository 标签的存取接口
+type TagRepository interface {
+	ExistByID(id int) (bool, error)
+	ExistByName(name string) (bool, error)
+	// ExistByIDs 检查 ids 中的标签是否全部存在
+	ExistByIDs(ids []int) (bool, error)
+
+	List(pageNum int, pageSize int, maps interface{}) ([]Tag, error)
+	Count(maps interface{}) (int, error)
+
+	Add(name string, state int, createdBy string) error
+	Edit(id int, data interface{}) error
+	// Delete 删除标签及其与文章的关联
+	Delete(id int) error
+	// GetArticleIDs 获取带有该标签的文章ID
+	GetArticleIDs(tagID int) ([]int, error)
+}
+
+// CategoryRepository 分类的存取接口
+type CategoryRepository interface {
+	ExistByID(id int) (bool, error)
+	// ExistByName 检查同一父分类下是否已有同名分类，excludeID 为需要排除的分类ID
+	ExistByName(name string, parentID int, excludeID int) (bool, error)
+
+	Get(id int) (*Category, error)
+	// List 获取分类，按路径排序以保证父分类在子分类之前
+	List(maps interface{}) ([]*Category, error)
+	// GetDescendantIDs 获取分类自身及其所有子孙分类的ID，分类不存在时为空
+	GetDescendantIDs(id int) ([]int, error)
+	CountChildren(id int) (int, error)
+	// GetArticleIDs 获取属于指定分类的文章ID
+	GetArticleIDs(categoryIDs []int) ([]int, error)
+
+	// Add 新增分类，parentPath 为父分类的路径，顶级分类传空字符串
+	Add(name string, parentID int, parentPath string, state int, createdBy string) error
+	Edit(id int, data interface{}) error
+	// Move 将分类移动到 parent 下并更新整棵子树的路径，parent 为空记录时移动为顶级分类
+	Move(id int, parent *Category, modifiedBy string) error
+	// Delete 删除分类，并将属于该分类的文章置为未分类
+	Delete(id int) error
+}
+
+// CommentRepository 评论的存取接口
+type CommentRepository interface {
+	ExistByID(id int) (bool, error)
+	Get(id int) (*Comment, error)
+	// List 按条件获取评论，按ID升序，pageSize <= 0 时不分页
+	List(pageNum int, pageSize int, maps interface{}) ([]*Comment, error)
+	Count(maps interface{}) (int, error)
+
+	Add(data map[string]interface{}) error
+	Edit(id int, data interface{}) error
+	// Delete 批量删除评论，用于连同回复一起删除
+	Delete(ids []int) error
+}
+
+// UserRepository 用户的存取接口，密码均为哈希后的值
+type UserRepository interface {
+	ExistByID(id int) (bool, error)
+	ExistByUsername(username string) (bool, error)
+	GetByUsername(username string) (*User, error)
+
+	Add(username, password, role string) error
+	UpdatePassword(id int, password string) error
+	UpdateRole(id int, role string) error
+	// CanStorePasswordHash 返回 password 列是否足够保存 bcrypt 哈希
+	CanStorePasswordHash() (bool, error)
+}
+
+// Repositories 服务层使用的全部仓库，由 service.New 注入到各个服务中
+type Repositories struct {
+	Articles   ArticleRepository
+	Tags       TagRepository
+	Categories CategoryRepository
+	Comments   CommentRepository
+	Users      UserRepository
+}
+
+// NewRepositories 返回基于 GORM 的仓库，使用 SetUp 建立的数据库连接
+func NewRepositories() *Repositories {
+	return &Repositories{
+		Articles:   gormArticleRepository{},
+		Tags:       gormTagRepository{},
+		Categories: gormCategoryRepository{},
+		Comments:   gormCommentRepository{},
+		Users:      gormUserRepository{},
+	}
+}
+
+// gormArticleRepository 把 ArticleRepository 转发到本包基于 GORM 的函数
+type gormArticleRepository struct{}
+
+func (gormArticleRepository) ExistByID(id int) (bool, error) { return ExistArticleByID(id) }
+
+func (gormArticleRepository) ExistBySlug(slug string, excludeID int) (bool, error) {
+	return ExistArticleBySlug(slug, excludeID)
+}
+
+func (gormArticleRepository) GetIDBySlug(slug string) (int, error) { return GetArticleIDBySlug(slug) }
+
+func (gormArticleRepository) Get(id int) (*Article, error) { return GetArticle(id) }
+
+func (gormArticleRepository) GetAll() ([]*Article, error) { return GetAllArticles() }
+
+func (gormArticleRepository) List(pageNum int, pageSize int, maps interface{}, filter *ArticleFilter) ([]*Article, error) {
+	return GetArticles(pageNum, pageSize, maps, filter)
+}
+
+func (gormArticleRepository) Count(maps interface{}, filter *ArticleFilter) (int, error) {
+	return GetArticleTotal(maps, filter)
+}
+
+func (gormArticleRepository) Add(data map[string]interface{}) (int, error) { return AddArticle(data) }
+
+func (gormArticleRepository) Update(id int, data map[string]interface{}) error {
+	return UpdateArticle(id, data)
+}
+
+func (gormArticleRepository) Delete(id int) error { return DeleteArticle(id) }
+
+func (gormArticleRepository) AddViews(views map[int]int) error { return AddArticleViews(views) }
+
+func (gormArticleRepository) PublishDue(now int64) ([]int, error) { return PublishDueArticles(now) }
+
+func (gormArticleRepository) GetRevisions(articleID int, pageNum int, pageSize int) ([]*ArticleRevision, error) {
+	return GetArticleRevisions(articleID, pageNum, pageSize)
+}
+
+func (gormArticleRepository) CountRevisions(articleID int) (int, error) {
+	return GetArticleRevisionTotal(articleID)
+}
+
+func (gormArticleRepository) GetRevision(articleID int, revision int) (*ArticleRevision, error) {
+	return GetArticleRevision(articleID, revision)
+}
+
+func (gormArticleRepository) ExistRevision(articleID int, revision int) (bool, error) {
+	return ExistArticleRevision(articleID, revision)
+}
+
+func (gormArticleRepository) RestoreRevision(articleID int, revision *ArticleRevision, modifiedBy int) error {
+	return RestoreArticleRevision(articleID, revision, modifiedBy)
+}
+
+func (gormArticleRepository) Search(query string, state int, pageNum int, pageSize int) ([]*ArticleSearchResult, int, error) {
+	return SearchArticles(query, state, pageNum, pageSize)
+}
+
+// gormTagRepository 把 TagRepository 转发到本包基于 GORM 的函数
+type gormTagRepository struct{}
+
+func (gormTagRepository) ExistByID(id int) (bool, error) { return ExistTagByID(id) }
+
+func (gormTagRepository) ExistByName(name string) (bool, error) { return ExistTagByName(name) }
+
+func (gormTagRepository) ExistByIDs(ids []int) (bool, error) { return ExistTagsByIDs(ids) }
+
+func (gormTagRepository) List(pageNum int, pageSize int, maps interface{}) ([]Tag, error) {
+	return GetTags(pageNum, pageSize, maps)
+}
+
+func (gormTagRepository) Count(maps interface{}) (int, error) { return GetTagTotal(maps) }
+
+func (gormTagRepository) Add(name string, state int, createdBy string) error {
+	return AddTag(name, state, createdBy)
+}
+
+func (gormTagRepository) Edit(id int, data interface{}) error { return EditTag(id, data) }
+
+func (gormTagRepository) Delete(id int) error { return DeleteTag(id) }
+
+func (gormTagRepository) GetArticleIDs(tagID int) ([]int, error) { return GetArticleIDsByTag(tagID) }
+
+// gormCategoryRepository 把 CategoryRepository 转发到本包基于 GORM 的函数
+type gormCategoryRepository struct{}
+
+func (gormCategoryRepository) ExistByID(id int) (bool, error) { return ExistCategoryByID(id) }
+
+func (gormCategoryRepository) ExistByName(name string, parentID int, excludeID int) (bool, error) {
+	return ExistCategoryByName(name, parentID, excludeID)
+}
+
+func (gormCategoryRepository) Get(id int) (*Category, error) { return GetCategory(id) }
+
+func (gormCategoryRepository) List(maps interface{}) ([]*Category, error) { return GetCategories(maps) }
+
+func (gormCategoryRepository) GetDescendantIDs(id int) ([]int, error) {
+	return GetCategoryDescendantIDs(id)
+}
+
+func (gormCategoryRepository) CountChildren(id int) (int, error) { return GetCategoryChildrenTotal(id) }
+
+func (gormCategoryRepository) GetArticleIDs(categoryIDs []int) ([]int, error) {
+	return GetArticleIDsByCategories(categoryIDs)
+}
+
+func (gormCategoryRepository) Add(name string, parentID int, parentPath string, state int, createdBy string) error {
+	return AddCategory(name, parentID, parentPath, state, createdBy)
+}
+
+func (gormCategoryRepository) Edit(id int, data interface{}) error { return EditCategory(id, data) }
+
+func (gormCategoryRepository) Move(id int, parent *Category, modifiedBy string) error {
+	return MoveCategory(id, parent, modifiedBy)
+}
+
+func (gormCategoryRepository) Delete(id int) error { return DeleteCategory(id) }
+
+// gormCommentRepository 把 CommentRepository 转发到本包基于 GORM 的函数
+type gormCommentRepository struct{}
+
+func (gormCommentRepository) ExistByID(id int) (bool, error) { return ExistCommentByID(id) }
+
+func (gormCommentRepository) Get(id int) (*Comment, error) { return GetComment(id) }
+
+func (gormCommentRepository) List(pageNum int, pageSize int, maps interface{}) ([]*Comment, error) {
+	return GetComments(pageNum, pageSize, maps)
+}
+
+func (gormCommentRepository) Count(maps interface{}) (int, error) { return GetCommentTotal(maps) }
+
+func (gormCommentRepository) Add(data map[string]interface{}) error { return AddComment(data) }
+
+func (gormCommentRepository) Edit(id int, data interface{}) error { return EditComment(id, data) }
+
+func (gormCommentRepository) Delete(ids []int) error { return DeleteComments(ids) }
+
+// gormUserRepository 把 UserRepository 转发到本包基于 GORM 的函数
+type gormUserRepository struct{}
+
+func (gormUserRepository) ExistByID(id int) (bool, error) { return ExistUserByID(id) }
+
+func (gormUserRepository) ExistByUsername(username string) (bool, error) {
+	return ExistUserByUsername(username)
+}
+
+func (gormUserRepository) GetByUsername(username string) (*User, error) {
+	return GetUserByUsername(username)
+}
+
+func (gormUserRepository) Add(username, password, role string) error {
+	return AddUser(username, password, role)
+}
+
+func (gormUserRepository) UpdatePassword(id int, password string) error {
+	return UpdateUserPassword(id, password)
+}
+
+func (gormUserRepository) UpdateRole(id int, role string) error { return UpdateUserRole(id, role) }
+
+func (gormUserRepository) CanStorePasswordHash() (bool, error) { return CanStorePasswordHash() }
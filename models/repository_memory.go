@@ -0,0 +1,1109 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMemoryRepositories 返回共享同一份内存数据的仓库，不需要数据库，用于单元测试
+func NewMemoryRepositories() *Repositories {
+	s := &memoryStore{
+		lastIDs:     make(map[string]int),
+		articles:    make(map[int]*Article),
+		articleTags: make(map[int][]int),
+		revisions:   make(map[int][]*ArticleRevision),
+		tags:        make(map[int]*Tag),
+		categories:  make(map[int]*Category),
+		comments:    make(map[int]*Comment),
+		users:       make(map[int]*User),
+	}
+	return &Repositories{
+		Articles:   &memoryArticleRepository{s},
+		Tags:       &memoryTagRepository{s},
+		Categories: &memoryCategoryRepository{s},
+		Comments:   &memoryCommentRepository{s},
+		Users:      &memoryUserRepository{s},
+	}
+}
+
+// memoryStore 内存仓库的数据，文章、标签、分类之间存在关联，因此所有仓库共用一把锁
+type memoryStore struct {
+	mu sync.RWMutex
+
+	lastIDs     map[string]int // 各表最后分配的自增ID
+	articles    map[int]*Article
+	articleTags map[int][]int              // 文章ID到标签ID
+	revisions   map[int][]*ArticleRevision // 文章ID到按版本号升序的版本
+	tags        map[int]*Tag
+	categories  map[int]*Category
+	comments    map[int]*Comment
+	users       map[int]*User
+}
+
+// nextID 与数据库的自增主键一样，每张表单独从 1 开始分配
+func (s *memoryStore) nextID(table string) int {
+	s.lastIDs[table]++
+	return s.lastIDs[table]
+}
+
+// articleIDs 返回按ID升序的文章ID，与数据库未指定排序时的结果一致
+func (s *memoryStore) articleIDs() []int {
+	ids := make([]int, 0, len(s.articles))
+	for id := range s.articles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// addRevision 保存文章当前内容的快照，规则与 addArticleRevision 一致
+func (s *memoryStore) addRevision(articleID int, createdBy int, restoredFrom int) {
+	article := s.articles[articleID]
+	if createdBy == 0 {
+		createdBy = article.ModifiedBy
+	}
+	if createdBy == 0 {
+		createdBy = article.CreatedBy
+	}
+
+	now := int(time.Now().Unix())
+	revisions := s.revisions[articleID]
+	s.revisions[articleID] = append(revisions, &ArticleRevision{
+		Model:        Model{ID: s.nextID("article_revision"), CreatedOn: now, ModifiedOn: now},
+		ArticleID:    articleID,
+		Revision:     len(revisions) + 1,
+		Title:        article.Title,
+		Desc:         article.Desc,
+		Content:      article.Content,
+		CreatedBy:    createdBy,
+		RestoredFrom: restoredFrom,
+	})
+}
+
+type memoryArticleRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryArticleRepository) ExistByID(id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.articles[id]
+	return ok, nil
+}
+
+func (r *memoryArticleRepository) ExistBySlug(slug string, excludeID int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for id, article := range r.s.articles {
+		if article.Slug == slug && id != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryArticleRepository) GetIDBySlug(slug string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for id, article := range r.s.articles {
+		if article.Slug == slug {
+			return id, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *memoryArticleRepository) Get(id int) (*Article, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if _, ok := r.s.articles[id]; !ok {
+		return &Article{}, nil
+	}
+	return r.withRelations(id), nil
+}
+
+func (r *memoryArticleRepository) GetAll() ([]*Article, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	articles := make([]*Article, 0, len(r.s.articles))
+	for _, id := range r.s.articleIDs() {
+		article := *r.s.articles[id]
+		articles = append(articles, &article)
+	}
+	return articles, nil
+}
+
+func (r *memoryArticleRepository) List(pageNum int, pageSize int, maps interface{}, filter *ArticleFilter) ([]*Article, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids, err := r.find(maps, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids = paginate(ids, pageNum, pageSize)
+	articles := make([]*Article, 0, len(ids))
+	for _, id := range ids {
+		articles = append(articles, r.withRelations(id))
+	}
+	return articles, nil
+}
+
+func (r *memoryArticleRepository) Count(maps interface{}, filter *ArticleFilter) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids, err := r.find(maps, filter)
+	return len(ids), err
+}
+
+func (r *memoryArticleRepository) Add(data map[string]interface{}) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	article := &Article{}
+	if err := setColumns(data, func(name string) interface{} { return articleColumn(article, name) }); err != nil {
+		return 0, err
+	}
+	if article.PublishAt <= 0 {
+		article.PublishAt, article.ScheduledBy = 0, 0
+	}
+	if err := r.checkSlug(article.Slug, 0); err != nil {
+		return 0, err
+	}
+
+	now := int(time.Now().Unix())
+	article.ID = r.s.nextID("article")
+	article.CreatedOn, article.ModifiedOn = now, now
+	r.s.articles[article.ID] = article
+
+	tagIDs, _ := data["tag_ids"].([]int)
+	r.s.articleTags[article.ID] = uniqueInts(tagIDs)
+	r.s.addRevision(article.ID, article.CreatedBy, 0)
+	return article.ID, nil
+}
+
+func (r *memoryArticleRepository) Update(id int, data map[string]interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.update(id, data, 0)
+}
+
+// update 规则与 updateArticle 一致，没有版本的文章先保存修改前的内容
+func (r *memoryArticleRepository) update(id int, data map[string]interface{}, restoredFrom int) error {
+	article, ok := r.s.articles[id]
+	if !ok {
+		return fmt.Errorf("models: article %d not found", id)
+	}
+
+	updated := *article
+	if err := setColumns(data, func(name string) interface{} { return articleColumn(&updated, name) }); err != nil {
+		return err
+	}
+	if err := r.checkSlug(updated.Slug, id); err != nil {
+		return err
+	}
+
+	if len(r.s.revisions[id]) == 0 {
+		r.s.addRevision(id, 0, 0)
+	}
+
+	updated.ModifiedOn = int(time.Now().Unix())
+	r.s.articles[id] = &updated
+	if tagIDs, ok := data["tag_ids"].([]int); ok {
+		r.s.articleTags[id] = uniqueInts(tagIDs)
+	}
+
+	modifiedBy, _ := data["modified_by"].(int)
+	r.s.addRevision(id, modifiedBy, restoredFrom)
+	return nil
+}
+
+func (r *memoryArticleRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.articles, id)
+	delete(r.s.articleTags, id)
+	return nil
+}
+
+func (r *memoryArticleRepository) AddViews(views map[int]int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, n := range views {
+		if article, ok := r.s.articles[id]; ok {
+			article.Views += n
+		}
+	}
+	return nil
+}
+
+func (r *memoryArticleRepository) PublishDue(now int64) ([]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var ids []int
+	for _, id := range r.s.articleIDs() {
+		article := r.s.articles[id]
+		if article.State == 0 && article.PublishAt > 0 && int64(article.PublishAt) <= now {
+			if len(r.s.revisions[id]) == 0 {
+				r.s.addRevision(id, 0, 0)
+			}
+
+			scheduledBy := article.ScheduledBy
+			article.State = 1
+			article.ModifiedBy = scheduledBy
+			article.PublishAt, article.ScheduledBy = 0, 0
+			article.ModifiedOn = int(time.Now().Unix())
+			r.s.addRevision(id, scheduledBy, 0)
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryArticleRepository) GetRevisions(articleID int, pageNum int, pageSize int) ([]*ArticleRevision, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// 按版本号倒序，不包含正文
+	all := r.s.revisions[articleID]
+	revisions := make([]*ArticleRevision, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		rev := *all[i]
+		rev.Content = ""
+		revisions = append(revisions, &rev)
+	}
+	return paginate(revisions, pageNum, pageSize), nil
+}
+
+func (r *memoryArticleRepository) CountRevisions(articleID int) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return len(r.s.revisions[articleID]), nil
+}
+
+func (r *memoryArticleRepository) GetRevision(articleID int, revision int) (*ArticleRevision, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if rev := r.revision(articleID, revision); rev != nil {
+		copied := *rev
+		return &copied, nil
+	}
+	return &ArticleRevision{}, nil
+}
+
+func (r *memoryArticleRepository) ExistRevision(articleID int, revision int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.revision(articleID, revision) != nil, nil
+}
+
+func (r *memoryArticleRepository) RestoreRevision(articleID int, revision *ArticleRevision, modifiedBy int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.update(articleID, map[string]interface{}{
+		"title":       revision.Title,
+		"desc":        revision.Desc,
+		"content":     revision.Content,
+		"modified_by": modifiedBy,
+	}, revision.Revision)
+}
+
+func (r *memoryArticleRepository) revision(articleID int, revision int) *ArticleRevision {
+	for _, rev := range r.s.revisions[articleID] {
+		if rev.Revision == revision {
+			return rev
+		}
+	}
+	return nil
+}
+
+// find 返回满足条件的文章ID，按ID升序
+func (r *memoryArticleRepository) find(maps interface{}, filter *ArticleFilter) ([]int, error) {
+	var ids []int
+	for _, id := range r.s.articleIDs() {
+		article := r.s.articles[id]
+		ok, err := matchColumns(maps, func(name string) interface{} { return articleColumn(article, name) })
+		if err != nil {
+			return nil, err
+		}
+		if ok && r.matchFilter(article, filter) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryArticleRepository) matchFilter(article *Article, filter *ArticleFilter) bool {
+	if filter == nil {
+		return true
+	}
+
+	if len(filter.TagIDs) > 0 {
+		want := uniqueInts(filter.TagIDs)
+		matched := 0
+		for _, tagID := range want {
+			if containsInt(r.s.articleTags[article.ID], tagID) {
+				matched++
+			}
+		}
+		if matched == 0 || filter.MatchAllTags && matched < len(want) {
+			return false
+		}
+	}
+
+	if len(filter.CategoryIDs) > 0 && !containsInt(filter.CategoryIDs, article.CategoryID) {
+		return false
+	}
+
+	return true
+}
+
+// withRelations 返回文章的副本并填充标签和分类，调用方需持有锁
+func (r *memoryArticleRepository) withRelations(id int) *Article {
+	article := *r.s.articles[id]
+	if category, ok := r.s.categories[article.CategoryID]; ok {
+		article.Category = *category
+	}
+	article.Tags = []Tag{}
+	for _, tagID := range r.s.articleTags[id] {
+		if tag, ok := r.s.tags[tagID]; ok {
+			article.Tags = append(article.Tags, *tag)
+		}
+	}
+	sort.Slice(article.Tags, func(i, j int) bool { return article.Tags[i].ID < article.Tags[j].ID })
+	return &article
+}
+
+// checkSlug 模拟 slug 列上的唯一索引
+func (r *memoryArticleRepository) checkSlug(slug string, id int) error {
+	for otherID, other := range r.s.articles {
+		if other.Slug == slug && otherID != id {
+			return fmt.Errorf("models: duplicate slug %q", slug)
+		}
+	}
+	return nil
+}
+
+type memoryTagRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryTagRepository) ExistByID(id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.tags[id]
+	return ok, nil
+}
+
+func (r *memoryTagRepository) ExistByName(name string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, tag := range r.s.tags {
+		if tag.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryTagRepository) ExistByIDs(ids []int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, id := range ids {
+		if _, ok := r.s.tags[id]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// List 与 GetTags 一致，pageNum 和 pageSize 都大于 0 时才分页
+func (r *memoryTagRepository) List(pageNum int, pageSize int, maps interface{}) ([]Tag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	tags, err := r.find(maps)
+	if err != nil {
+		return nil, err
+	}
+	if pageNum > 0 && pageSize > 0 {
+		tags = paginate(tags, pageNum, pageSize)
+	}
+	return tags, nil
+}
+
+func (r *memoryTagRepository) Count(maps interface{}) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	tags, err := r.find(maps)
+	return len(tags), err
+}
+
+func (r *memoryTagRepository) Add(name string, state int, createdBy string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := int(time.Now().Unix())
+	id := r.s.nextID("tag")
+	r.s.tags[id] = &Tag{
+		Model:     Model{ID: id, CreatedOn: now, ModifiedOn: now},
+		Name:      name,
+		CreatedBy: createdBy,
+		State:     state,
+	}
+	return nil
+}
+
+func (r *memoryTagRepository) Edit(id int, data interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tag, ok := r.s.tags[id]
+	if !ok {
+		return nil
+	}
+
+	updated := *tag
+	if err := setColumns(data, func(name string) interface{} { return tagColumn(&updated, name) }); err != nil {
+		return err
+	}
+	updated.ModifiedOn = int(time.Now().Unix())
+	r.s.tags[id] = &updated
+	return nil
+}
+
+func (r *memoryTagRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.tags, id)
+	for articleID, tagIDs := range r.s.articleTags {
+		r.s.articleTags[articleID] = removeInt(tagIDs, id)
+	}
+	return nil
+}
+
+func (r *memoryTagRepository) GetArticleIDs(tagID int) ([]int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var ids []int
+	for articleID, tagIDs := range r.s.articleTags {
+		if containsInt(tagIDs, tagID) {
+			ids = append(ids, articleID)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// find 返回满足条件的标签副本，按ID升序
+func (r *memoryTagRepository) find(maps interface{}) ([]Tag, error) {
+	ids := make([]int, 0, len(r.s.tags))
+	for id := range r.s.tags {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var tags []Tag
+	for _, id := range ids {
+		tag := r.s.tags[id]
+		ok, err := matchColumns(maps, func(name string) interface{} { return tagColumn(tag, name) })
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+type memoryCategoryRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryCategoryRepository) ExistByID(id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.categories[id]
+	return ok, nil
+}
+
+func (r *memoryCategoryRepository) ExistByName(name string, parentID int, excludeID int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for id, category := range r.s.categories {
+		if category.Name == name && category.ParentID == parentID && id != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryCategoryRepository) Get(id int) (*Category, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if category, ok := r.s.categories[id]; ok {
+		copied := *category
+		return &copied, nil
+	}
+	return &Category{}, nil
+}
+
+func (r *memoryCategoryRepository) List(maps interface{}) ([]*Category, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var categories []*Category
+	for _, category := range r.s.categories {
+		ok, err := matchColumns(maps, func(name string) interface{} { return categoryColumn(category, name) })
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			copied := *category
+			categories = append(categories, &copied)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Path < categories[j].Path })
+	return categories, nil
+}
+
+func (r *memoryCategoryRepository) GetDescendantIDs(id int) ([]int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	category, ok := r.s.categories[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.s.subtree(category.Path), nil
+}
+
+func (r *memoryCategoryRepository) CountChildren(id int) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	count := 0
+	for _, category := range r.s.categories {
+		if category.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryCategoryRepository) GetArticleIDs(categoryIDs []int) ([]int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	ids := []int{}
+	for _, id := range r.s.articleIDs() {
+		if containsInt(categoryIDs, r.s.articles[id].CategoryID) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryCategoryRepository) Add(name string, parentID int, parentPath string, state int, createdBy string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := int(time.Now().Unix())
+	id := r.s.nextID("category")
+	r.s.categories[id] = &Category{
+		Model:     Model{ID: id, CreatedOn: now, ModifiedOn: now},
+		Name:      name,
+		ParentID:  parentID,
+		Path:      categoryPath(parentPath, id),
+		CreatedBy: createdBy,
+		State:     state,
+	}
+	return nil
+}
+
+func (r *memoryCategoryRepository) Edit(id int, data interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	category, ok := r.s.categories[id]
+	if !ok {
+		return nil
+	}
+
+	updated := *category
+	if err := setColumns(data, func(name string) interface{} { return categoryColumn(&updated, name) }); err != nil {
+		return err
+	}
+	updated.ModifiedOn = int(time.Now().Unix())
+	r.s.categories[id] = &updated
+	return nil
+}
+
+func (r *memoryCategoryRepository) Move(id int, parent *Category, modifiedBy string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	category, ok := r.s.categories[id]
+	if !ok {
+		return fmt.Errorf("models: category %d not found", id)
+	}
+
+	oldPath, newPath := category.Path, categoryPath(parent.Path, id)
+	for _, descendantID := range r.s.subtree(oldPath) {
+		descendant := *r.s.categories[descendantID]
+		descendant.Path = newPath + strings.TrimPrefix(descendant.Path, oldPath)
+		r.s.categories[descendantID] = &descendant
+	}
+
+	moved := r.s.categories[id]
+	moved.ParentID = parent.ID
+	moved.ModifiedBy = modifiedBy
+	moved.ModifiedOn = int(time.Now().Unix())
+	return nil
+}
+
+func (r *memoryCategoryRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.categories, id)
+	for _, article := range r.s.articles {
+		if article.CategoryID == id {
+			article.CategoryID = 0
+		}
+	}
+	return nil
+}
+
+// subtree 返回路径以 path 开头的分类ID，即分类自身及其子孙分类，按ID升序
+func (s *memoryStore) subtree(path string) []int {
+	var ids []int
+	for id, category := range s.categories {
+		if strings.HasPrefix(category.Path, path) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+type memoryCommentRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryCommentRepository) ExistByID(id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.comments[id]
+	return ok, nil
+}
+
+func (r *memoryCommentRepository) Get(id int) (*Comment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if comment, ok := r.s.comments[id]; ok {
+		copied := *comment
+		return &copied, nil
+	}
+	return &Comment{}, nil
+}
+
+func (r *memoryCommentRepository) List(pageNum int, pageSize int, maps interface{}) ([]*Comment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	comments, err := r.find(maps)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize > 0 {
+		comments = paginate(comments, pageNum, pageSize)
+	}
+	return comments, nil
+}
+
+func (r *memoryCommentRepository) Count(maps interface{}) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	comments, err := r.find(maps)
+	return len(comments), err
+}
+
+func (r *memoryCommentRepository) Add(data map[string]interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := int(time.Now().Unix())
+	id := r.s.nextID("comment")
+	r.s.comments[id] = &Comment{
+		Model:     Model{ID: id, CreatedOn: now, ModifiedOn: now},
+		ArticleID: data["article_id"].(int),
+		ParentID:  data["parent_id"].(int),
+		Content:   data["content"].(string),
+		CreatedBy: data["created_by"].(string),
+		State:     COMMENT_STATE_PENDING,
+	}
+	return nil
+}
+
+func (r *memoryCommentRepository) Edit(id int, data interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return nil
+	}
+
+	updated := *comment
+	if err := setColumns(data, func(name string) interface{} { return commentColumn(&updated, name) }); err != nil {
+		return err
+	}
+	updated.ModifiedOn = int(time.Now().Unix())
+	r.s.comments[id] = &updated
+	return nil
+}
+
+func (r *memoryCommentRepository) Delete(ids []int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.s.comments, id)
+	}
+	return nil
+}
+
+// find 返回满足条件的评论副本，按ID升序
+func (r *memoryCommentRepository) find(maps interface{}) ([]*Comment, error) {
+	ids := make([]int, 0, len(r.s.comments))
+	for id := range r.s.comments {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var comments []*Comment
+	for _, id := range ids {
+		comment := r.s.comments[id]
+		ok, err := matchColumns(maps, func(name string) interface{} { return commentColumn(comment, name) })
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	return comments, nil
+}
+
+type memoryUserRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryUserRepository) ExistByID(id int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	_, ok := r.s.users[id]
+	return ok, nil
+}
+
+func (r *memoryUserRepository) ExistByUsername(username string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.byUsername(username) != nil, nil
+}
+
+func (r *memoryUserRepository) GetByUsername(username string) (*User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if user := r.byUsername(username); user != nil {
+		copied := *user
+		return &copied, nil
+	}
+	return &User{}, nil
+}
+
+func (r *memoryUserRepository) Add(username, password, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// 模拟 username 列上的唯一索引
+	if r.byUsername(username) != nil {
+		return fmt.Errorf("models: duplicate username %q", username)
+	}
+
+	id := r.s.nextID("auth")
+	r.s.users[id] = &User{ID: id, Username: username, Password: password, Role: role}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(id int, password string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user, ok := r.s.users[id]; ok {
+		user.Password = password
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateRole(id int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user, ok := r.s.users[id]; ok {
+		user.Role = role
+	}
+	return nil
+}
+
+// CanStorePasswordHash 内存中的密码没有长度限制
+func (r *memoryUserRepository) CanStorePasswordHash() (bool, error) {
+	return true, nil
+}
+
+func (r *memoryUserRepository) byUsername(username string) *User {
+	for _, user := range r.s.users {
+		if user.Username == username {
+			return user
+		}
+	}
+	return nil
+}
+
+// articleColumn 返回文章表中列对应字段的指针，未知的列返回 nil
+func articleColumn(a *Article, name string) interface{} {
+	switch name {
+	case "id":
+		return &a.ID
+	case "created_on":
+		return &a.CreatedOn
+	case "modified_on":
+		return &a.ModifiedOn
+	case "deleted_on":
+		return &a.DeletedOn
+	case "category_id":
+		return &a.CategoryID
+	case "title":
+		return &a.Title
+	case "slug":
+		return &a.Slug
+	case "desc":
+		return &a.Desc
+	case "content":
+		return &a.Content
+	case "cover_image_url":
+		return &a.CoverImageUrl
+	case "created_by":
+		return &a.CreatedBy
+	case "modified_by":
+		return &a.ModifiedBy
+	case "state":
+		return &a.State
+	case "views":
+		return &a.Views
+	case "publish_at":
+		return &a.PublishAt
+	case "scheduled_by":
+		return &a.ScheduledBy
+	}
+	return nil
+}
+
+// tagColumn 返回标签表中列对应字段的指针，未知的列返回 nil
+func tagColumn(t *Tag, name string) interface{} {
+	switch name {
+	case "id":
+		return &t.ID
+	case "created_on":
+		return &t.CreatedOn
+	case "modified_on":
+		return &t.ModifiedOn
+	case "deleted_on":
+		return &t.DeletedOn
+	case "name":
+		return &t.Name
+	case "created_by":
+		return &t.CreatedBy
+	case "modified_by":
+		return &t.ModifiedBy
+	case "state":
+		return &t.State
+	}
+	return nil
+}
+
+// categoryColumn 返回分类表中列对应字段的指针，未知的列返回 nil
+func categoryColumn(c *Category, name string) interface{} {
+	switch name {
+	case "id":
+		return &c.ID
+	case "created_on":
+		return &c.CreatedOn
+	case "modified_on":
+		return &c.ModifiedOn
+	case "deleted_on":
+		return &c.DeletedOn
+	case "name":
+		return &c.Name
+	case "parent_id":
+		return &c.ParentID
+	case "path":
+		return &c.Path
+	case "created_by":
+		return &c.CreatedBy
+	case "modified_by":
+		return &c.ModifiedBy
+	case "state":
+		return &c.State
+	}
+	return nil
+}
+
+// commentColumn 返回评论表中列对应字段的指针，未知的列返回 nil
+func commentColumn(c *Comment, name string) interface{} {
+	switch name {
+	case "id":
+		return &c.ID
+	case "created_on":
+		return &c.CreatedOn
+	case "modified_on":
+		return &c.ModifiedOn
+	case "deleted_on":
+		return &c.DeletedOn
+	case "article_id":
+		return &c.ArticleID
+	case "parent_id":
+		return &c.ParentID
+	case "content":
+		return &c.Content
+	case "created_by":
+		return &c.CreatedBy
+	case "modified_by":
+		return &c.ModifiedBy
+	case "state":
+		return &c.State
+	}
+	return nil
+}
+
+// matchColumns 判断记录是否满足 maps 中的全部等值条件，maps 只支持 nil 和 map[string]interface{}
+func matchColumns(maps interface{}, column func(name string) interface{}) (bool, error) {
+	if maps == nil {
+		return true, nil
+	}
+	conditions, ok := maps.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("models: unsupported conditions %T", maps)
+	}
+
+	for name, want := range conditions {
+		switch field := column(name).(type) {
+		case *int:
+			if v, ok := want.(int); !ok || *field != v {
+				return false, nil
+			}
+		case *string:
+			if v, ok := want.(string); !ok || *field != v {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf("models: unknown column %q", name)
+		}
+	}
+	return true, nil
+}
+
+// setColumns 把 data 中的值写入记录。与 GORM 的 Updates 一样忽略不存在的列
+func setColumns(data interface{}, column func(name string) interface{}) error {
+	values, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("models: unsupported data %T", data)
+	}
+
+	for name, value := range values {
+		switch field := column(name).(type) {
+		case *int:
+			v, ok := value.(int)
+			if !ok {
+				return fmt.Errorf("models: column %q wants int, got %T", name, value)
+			}
+			*field = v
+		case *string:
+			v, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("models: column %q wants string, got %T", name, value)
+			}
+			*field = v
+		}
+	}
+	return nil
+}
+
+// paginate 与 SQL 的 OFFSET/LIMIT 一致，limit 小于 0 时不限制条数
+func paginate[T any](items []T, offset int, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	if offset > 0 {
+		items = items[offset:]
+	}
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInt(values []int, v int) []int {
+	result := values[:0]
+	for _, value := range values {
+		if value != v {
+			result = append(result, value)
+		}
+	}
+	return result
+}
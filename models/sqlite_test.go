@@ -0,0 +1,389 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+)
+
+// setUpSQLite 连接一个新的 SQLite 内存数据库并执行全部迁移
+func setUpSQLite(t *testing.T) {
+	t.Helper()
+
+	connectSQLite(t)
+	applied, err := MigrateUp()
+	if err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("MigrateUp applied %d migrations, want %d", len(applied), len(migrations))
+	}
+}
+
+// connectSQLite 连接一个新的 SQLite 内存数据库，不执行迁移
+func connectSQLite(t *testing.T) {
+	t.Helper()
+
+	saved := *setting.DatabaseSetting
+	*setting.DatabaseSetting = setting.Database{
+		Type:        setting.DB_SQLITE,
+		Name:        ":memory:",
+		TablePrefix: "blog_",
+	}
+	SetUp()
+	db.LogMode(false)
+	t.Cleanup(func() {
+		CloseDB()
+		*setting.DatabaseSetting = saved
+	})
+}
+
+func addTestArticle(t *testing.T, title, slug, content string, state int, tagIDs []int) int {
+	t.Helper()
+
+	id, err := AddArticle(map[string]interface{}{
+		"title":       title,
+		"slug":        slug,
+		"category_id": 0,
+		"desc":        "",
+		"content":     content,
+		"created_by":  1,
+		"state":       state,
+		"tag_ids":     tagIDs,
+	})
+	if err != nil {
+		t.Fatalf("AddArticle(%q): %v", slug, err)
+	}
+	return id
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	setUpSQLite(t)
+
+	applied, err := MigrateUp()
+	if err != nil {
+		t.Fatalf("second MigrateUp: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second MigrateUp applied %v, want none", applied)
+	}
+
+	statuses, err := MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("migration %s is not applied", status.ID)
+		}
+	}
+
+	rolledBack, err := MigrateDown(len(migrations))
+	if err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+	if len(rolledBack) != len(migrations) {
+		t.Fatalf("MigrateDown rolled back %d migrations, want %d", len(rolledBack), len(migrations))
+	}
+	if db.HasTable(&Article{}) {
+		t.Errorf("article table still exists after rolling back all migrations")
+	}
+
+	if _, err := MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp after MigrateDown: %v", err)
+	}
+}
+
+// createLegacyTables 按旧版手工建表的结构创建文章表和用户表
+func createLegacyTables(t *testing.T) {
+	t.Helper()
+
+	err := execAll(db,
+		"CREATE TABLE blog_article (id INTEGER PRIMARY KEY AUTOINCREMENT, tag_id INTEGER DEFAULT 0, "+
+			"title VARCHAR(100) DEFAULT '', \"desc\" VARCHAR(255) DEFAULT '', content TEXT, "+
+			"cover_image_url VARCHAR(255) DEFAULT '', created_on INTEGER DEFAULT 0, created_by INTEGER DEFAULT 0, "+
+			"modified_on INTEGER DEFAULT 0, modified_by INTEGER DEFAULT 0, deleted_on INTEGER DEFAULT 0, "+
+			"state SMALLINT DEFAULT 1, views INTEGER DEFAULT 0)",
+		"CREATE TABLE blog_auth (id INTEGER PRIMARY KEY AUTOINCREMENT, "+
+			"username VARCHAR(50) DEFAULT '', password VARCHAR(50) DEFAULT '')",
+		"INSERT INTO blog_auth (username, password) VALUES ('legacy', 'plaintext')",
+	)
+	if err != nil {
+		t.Fatalf("create legacy tables: %v", err)
+	}
+}
+
+func TestSQLiteLegacyMigration(t *testing.T) {
+	connectSQLite(t)
+	createLegacyTables(t)
+
+	passwordWidened.Store(false)
+	t.Cleanup(func() { passwordWidened.Store(false) })
+
+	widened, err := CanStorePasswordHash()
+	if err != nil || widened {
+		t.Fatalf("CanStorePasswordHash before MigrateUp = %v, %v, want false", widened, err)
+	}
+
+	if _, err := MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp on legacy tables: %v", err)
+	}
+	widened, err = CanStorePasswordHash()
+	if err != nil || !widened {
+		t.Fatalf("CanStorePasswordHash after MigrateUp = %v, %v, want true", widened, err)
+	}
+
+	user, err := GetUserByUsername("legacy")
+	if err != nil || user.Password != "plaintext" || user.Role != ROLE_READER {
+		t.Fatalf("legacy user = %+v, %v, want plaintext password and reader role", user, err)
+	}
+}
+
+func TestSQLiteArticleCoverImage(t *testing.T) {
+	setUpSQLite(t)
+
+	id, err := AddArticle(map[string]interface{}{
+		"title":           "Cover",
+		"slug":            "cover",
+		"category_id":     0,
+		"desc":            "",
+		"content":         "",
+		"created_by":      1,
+		"state":           1,
+		"tag_ids":         []int(nil),
+		"cover_image_url": "upload/a.png",
+	})
+	if err != nil {
+		t.Fatalf("AddArticle: %v", err)
+	}
+	if err := UpdateArticle(id, map[string]interface{}{"cover_image_url": "upload/b.png", "modified_by": 1}); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+
+	article, err := GetArticle(id)
+	if err != nil || article.CoverImageUrl != "upload/b.png" {
+		t.Fatalf("cover image = %q, %v, want upload/b.png", article.CoverImageUrl, err)
+	}
+}
+
+func TestSQLiteArticleCRUD(t *testing.T) {
+	setUpSQLite(t)
+
+	if err := AddTag("go", 1, "admin"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+	if err := AddTag("web", 1, "admin"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+
+	id := addTestArticle(t, "Hello", "hello", "first", 1, []int{1, 2})
+	addTestArticle(t, "Other", "other", "second", 1, []int{2})
+
+	article, err := GetArticle(id)
+	if err != nil {
+		t.Fatalf("GetArticle: %v", err)
+	}
+	if article.Title != "Hello" || len(article.Tags) != 2 {
+		t.Fatalf("GetArticle = %q with %d tags, want Hello with 2 tags", article.Title, len(article.Tags))
+	}
+
+	slugID, err := GetArticleIDBySlug("hello")
+	if err != nil || slugID != id {
+		t.Fatalf("GetArticleIDBySlug = %d, %v, want %d", slugID, err, id)
+	}
+
+	filter := &ArticleFilter{TagIDs: []int{1, 2}, MatchAllTags: true}
+	total, err := GetArticleTotal(map[string]interface{}{"state": 1}, filter)
+	if err != nil || total != 1 {
+		t.Fatalf("GetArticleTotal(all tags) = %d, %v, want 1", total, err)
+	}
+	articles, err := GetArticles(0, 10, map[string]interface{}{"state": 1}, &ArticleFilter{TagIDs: []int{2}})
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("GetArticles(any tag) returned %d articles, %v, want 2", len(articles), err)
+	}
+
+	if err := UpdateArticle(id, map[string]interface{}{"title": "Hello again", "modified_by": 2}); err != nil {
+		t.Fatalf("UpdateArticle: %v", err)
+	}
+	revisions, err := GetArticleRevisionTotal(id)
+	if err != nil || revisions != 2 {
+		t.Fatalf("GetArticleRevisionTotal = %d, %v, want 2", revisions, err)
+	}
+
+	// 只修改标签也会保存新版本，标签与版本在同一事务中写入
+	if err := UpdateArticle(id, map[string]interface{}{"tag_ids": []int{2}, "modified_by": 3}); err != nil {
+		t.Fatalf("UpdateArticle(tag_ids): %v", err)
+	}
+	revision, err := GetArticleRevision(id, 3)
+	if err != nil || revision.CreatedBy != 3 {
+		t.Fatalf("GetArticleRevision(3) = %+v, %v, want revision created by 3", revision, err)
+	}
+
+	if err := AddArticleViews(map[int]int{id: 3}); err != nil {
+		t.Fatalf("AddArticleViews: %v", err)
+	}
+	article, err = GetArticle(id)
+	if err != nil {
+		t.Fatalf("GetArticle: %v", err)
+	}
+	if article.Title != "Hello again" || article.Views != 3 || len(article.Tags) != 1 || article.Tags[0].ID != 2 {
+		t.Fatalf("GetArticle = %q with %d views and %d tags, want Hello again with 3 views and tag 2", article.Title, article.Views, len(article.Tags))
+	}
+
+	if err := DeleteArticle(id); err != nil {
+		t.Fatalf("DeleteArticle: %v", err)
+	}
+	exists, err := ExistArticleByID(id)
+	if err != nil || exists {
+		t.Fatalf("ExistArticleByID after delete = %v, %v, want false", exists, err)
+	}
+}
+
+func TestSQLiteSearchArticles(t *testing.T) {
+	setUpSQLite(t)
+
+	addTestArticle(t, "Intro", "intro", "this post mentions gin once", 1, nil)
+	titleID := addTestArticle(t, "Gin routing", "gin-routing", "routes", 1, nil)
+	addTestArticle(t, "Gin draft", "gin-draft", "draft", 0, nil)
+	addTestArticle(t, "Discount", "discount", "100% off", 1, nil)
+
+	results, total, err := SearchArticles("gin", 1, 0, 10)
+	if err != nil {
+		t.Fatalf("SearchArticles: %v", err)
+	}
+	if total != 2 || len(results) != 2 {
+		t.Fatalf("SearchArticles(gin) = %d results, total %d, want 2", len(results), total)
+	}
+	// 标题命中的文章排在前面
+	if results[0].ID != titleID {
+		t.Errorf("first result = %d, want %d", results[0].ID, titleID)
+	}
+
+	_, total, err = SearchArticles("gin", -1, 0, 10)
+	if err != nil || total != 3 {
+		t.Fatalf("SearchArticles(gin, any state) total = %d, %v, want 3", total, err)
+	}
+
+	// LIKE 的通配符按普通字符匹配
+	_, total, err = SearchArticles("0%", 1, 0, 10)
+	if err != nil || total != 1 {
+		t.Fatalf("SearchArticles(0%%) total = %d, %v, want 1", total, err)
+	}
+	_, total, err = SearchArticles("_", 1, 0, 10)
+	if err != nil || total != 0 {
+		t.Fatalf("SearchArticles(_) total = %d, %v, want 0", total, err)
+	}
+}
+
+func TestSQLiteCleanAllArticle(t *testing.T) {
+	setUpSQLite(t)
+
+	if err := AddTag("go", 1, "admin"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+	keptID := addTestArticle(t, "Kept", "kept", "", 1, []int{1})
+	deletedID := addTestArticle(t, "Deleted", "deleted", "", 1, []int{1})
+
+	if err := db.Model(&Article{}).Where("id = ?", deletedID).UpdateColumn("deleted_on", 1).Error; err != nil {
+		t.Fatalf("mark article deleted: %v", err)
+	}
+
+	if err := CleanAllArticle(); err != nil {
+		t.Fatalf("CleanAllArticle: %v", err)
+	}
+
+	for id, want := range map[int]bool{keptID: true, deletedID: false} {
+		exists, err := ExistArticleByID(id)
+		if err != nil || exists != want {
+			t.Errorf("ExistArticleByID(%d) = %v, %v, want %v", id, exists, err, want)
+		}
+	}
+
+	articleIDs, err := GetArticleIDsByTag(1)
+	if err != nil {
+		t.Fatalf("GetArticleIDsByTag: %v", err)
+	}
+	if len(articleIDs) != 1 || articleIDs[0] != keptID {
+		t.Errorf("GetArticleIDsByTag = %v, want [%d]", articleIDs, keptID)
+	}
+}
+
+func TestSQLitePublishDueArticles(t *testing.T) {
+	setUpSQLite(t)
+
+	addScheduled := func(slug string, publishAt int) int {
+		t.Helper()
+		id, err := AddArticle(map[string]interface{}{
+			"title": slug, "slug": slug, "category_id": 0, "desc": "", "content": "",
+			"created_by": 1, "state": 0, "tag_ids": []int{},
+			"publish_at": publishAt, "scheduled_by": 5,
+		})
+		if err != nil {
+			t.Fatalf("AddArticle(%q): %v", slug, err)
+		}
+		return id
+	}
+	dueID := addScheduled("due", 100)
+	laterID := addScheduled("later", 300)
+
+	ids, err := PublishDueArticles(200)
+	if err != nil || len(ids) != 1 || ids[0] != dueID {
+		t.Fatalf("PublishDueArticles = %v, %v, want [%d]", ids, err, dueID)
+	}
+
+	article, err := GetArticle(dueID)
+	if err != nil {
+		t.Fatalf("GetArticle: %v", err)
+	}
+	if article.State != 1 || article.PublishAt != 0 || article.ScheduledBy != 0 || article.ModifiedBy != 5 {
+		t.Errorf("published article = state %d, publish_at %d, scheduled_by %d, modified_by %d, want 1, 0, 0, 5",
+			article.State, article.PublishAt, article.ScheduledBy, article.ModifiedBy)
+	}
+	revision, err := GetArticleRevision(dueID, 2)
+	if err != nil || revision.CreatedBy != 5 {
+		t.Errorf("GetArticleRevision(2) = %+v, %v, want revision created by 5", revision, err)
+	}
+
+	// 已发布的文章不会被再次发布
+	ids, err = PublishDueArticles(200)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("second PublishDueArticles = %v, %v, want none", ids, err)
+	}
+	if total, err := GetArticleRevisionTotal(laterID); err != nil || total != 1 {
+		t.Errorf("GetArticleRevisionTotal(later) = %d, %v, want 1", total, err)
+	}
+}
+
+func TestSQLiteMoveCategory(t *testing.T) {
+	setUpSQLite(t)
+
+	for _, c := range []struct {
+		name       string
+		parentID   int
+		parentPath string
+	}{
+		{"backend", 0, ""},  // 1
+		{"go", 1, "/1/"},    // 2
+		{"gin", 2, "/1/2/"}, // 3
+		{"frontend", 0, ""}, // 4
+	} {
+		if err := AddCategory(c.name, c.parentID, c.parentPath, 1, "admin"); err != nil {
+			t.Fatalf("AddCategory(%q): %v", c.name, err)
+		}
+	}
+
+	parent, err := GetCategory(4)
+	if err != nil {
+		t.Fatalf("GetCategory: %v", err)
+	}
+	if err := MoveCategory(2, parent, "editor"); err != nil {
+		t.Fatalf("MoveCategory: %v", err)
+	}
+
+	for id, want := range map[int]string{1: "/1/", 2: "/4/2/", 3: "/4/2/3/"} {
+		category, err := GetCategory(id)
+		if err != nil || category.Path != want {
+			t.Errorf("category %d path = %q, %v, want %q", id, category.Path, err, want)
+		}
+	}
+}
@@ -73,11 +73,29 @@ func ExistTagByID(id int) (bool, error) {
 	return tag.ID > 0, nil
 }
 
+// ExistTagsByIDs 检查 ids 中的标签是否全部存在
+func ExistTagsByIDs(ids []int) (bool, error) {
+	ids = uniqueInts(ids)
+
+	var count int
+	if err := db.Model(&Tag{}).Where("id IN (?)", ids).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == len(ids), nil
+}
+
 func DeleteTag(id int) error {
-	if err := db.Where("id = ?", id).Delete(&Tag{}).Error; err != nil {
+	tx := db.Begin()
+	if err := tx.Where("id = ?", id).Delete(&Tag{}).Error; err != nil {
+		tx.Rollback()
 		return err
 	}
-	return nil
+
+	if err := tx.Where("tag_id = ?", id).Delete(ArticleTag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
 func EditTag(id int, data interface{}) error {
@@ -1,12 +1,79 @@
 package app
 
 import (
+	"strconv"
+
 	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
 	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
 )
 
+// CLAIMS_KEY JWT 中间件将解析后的 Claims 存入 gin.Context 时使用的键
+const CLAIMS_KEY = "claims"
+
+// REQUEST_ID_KEY requestid 中间件将请求ID存入 gin.Context 时使用的键
+const REQUEST_ID_KEY = "request_id"
+
 func MakrErrors(errors []*validation.Error) {
 	for _, err := range errors {
 		logging.Info(err.Key, err.Message)
 	}
 }
+
+// GetClaims 获取 JWT 中间件解析出的当前用户信息
+func GetClaims(c *gin.Context) (*util.Claims, bool) {
+	v, ok := c.Get(CLAIMS_KEY)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*util.Claims)
+	return claims, ok
+}
+
+// GetRequestID 获取 requestid 中间件分配的请求ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(REQUEST_ID_KEY)
+}
+
+// GetVisitor 获取访客标识，已登录用户使用用户ID，否则使用客户端IP
+func GetVisitor(c *gin.Context) string {
+	if claims, ok := GetClaims(c); ok && claims.UserID > 0 {
+		return "u" + strconv.Itoa(claims.UserID)
+	}
+	return "ip" + c.ClientIP()
+}
+
+// GetTagFilter 解析按标签过滤文章的查询参数：tag_ids 为逗号分隔的标签ID（兼容单个 tag_id），
+// tag_match 为 all 时要求文章包含全部标签，为 any 或为空时包含任一标签即可
+func GetTagFilter(c *gin.Context, valid *validation.Validation) ([]int, bool) {
+	arg := c.Query("tag_ids")
+	if arg == "" {
+		arg = c.Query("tag_id")
+	}
+
+	tagIDs, err := util.ParseIDs(arg)
+	if err != nil {
+		valid.SetError("tag_ids", "标签ID必须大于0")
+	}
+
+	match := c.DefaultQuery("tag_match", "any")
+	if match != "any" && match != "all" {
+		valid.SetError("tag_match", "匹配方式只允许any或all")
+	}
+
+	return tagIDs, match == "all"
+}
+
+// GetCategoryFilter 解析按分类过滤文章的查询参数：category_id 为分类ID，
+// sub_categories 为 1 或 true 时同时包含子孙分类下的文章
+func GetCategoryFilter(c *gin.Context, valid *validation.Validation) (int, bool) {
+	categoryID := 0
+	if arg := c.Query("category_id"); arg != "" {
+		categoryID, _ = strconv.Atoi(arg)
+		valid.Min(categoryID, 1, "category_id").Message("分类ID必须大于0")
+	}
+
+	sub, _ := strconv.ParseBool(c.DefaultQuery("sub_categories", "false"))
+	return categoryID, sub
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/3Eeeecho/go-gin-example/pkg/e"
 	"github.com/gin-gonic/gin"
 )
@@ -13,12 +17,28 @@ type Response struct {
 	Code int         `json:"code"`
 	Msg  string      `json:"msg"`
 	Data interface{} `json:"data"`
+	// RequestID 仅在出错时返回，方便根据用户反馈查找日志
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func (g *Gin) Response(httpStatus, errCode int, data interface{}) {
-	g.C.JSON(httpStatus, &Response{
+	resp := &Response{
 		Code: errCode,
 		Msg:  e.GetMsg(errCode),
 		Data: data,
-	})
+	}
+	if errCode != e.SUCCESS {
+		resp.RequestID = GetRequestID(g.C)
+	}
+	g.C.JSON(httpStatus, resp)
+}
+
+// ResponseRetryAfter 返回错误响应并通过 Retry-After 告知客户端多少秒后重试
+func (g *Gin) ResponseRetryAfter(httpStatus, errCode int, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	g.C.Header("Retry-After", strconv.Itoa(seconds))
+	g.Response(httpStatus, errCode, nil)
 }
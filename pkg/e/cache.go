@@ -3,4 +3,8 @@ package e
 const (
 	CACHE_ARTICLE = "ARTICLE"
 	CACHE_TAG     = "TAG"
+	CACHE_COMMENT = "COMMENT"
+	CACHE_TOKEN   = "TOKEN"
+	CACHE_LIMIT   = "RATELIMIT"
+	CACHE_LOGIN   = "LOGIN"
 )
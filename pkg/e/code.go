@@ -27,12 +27,59 @@ const (
 	ERROR_GET_ARTICLE_FAIL         = 10018
 	ERROR_GEN_ARTICLE_POSTER_FAIL  = 10019
 
+	ERROR_NOT_EXIST_COMMENT        = 10020
+	ERROR_CHECK_EXIST_COMMENT_FAIL = 10021
+	ERROR_ADD_COMMENT_FAIL         = 10022
+	ERROR_EDIT_COMMENT_FAIL        = 10023
+	ERROR_DELETE_COMMENT_FAIL      = 10024
+	ERROR_COUNT_COMMENT_FAIL       = 10025
+	ERROR_GET_COMMENTS_FAIL        = 10026
+	ERROR_AUDIT_COMMENT_FAIL       = 10027
+	ERROR_NOT_EXIST_PARENT_COMMENT = 10028
+	ERROR_EXIST_ARTICLE_SLUG       = 10029
+	ERROR_SEARCH_ARTICLES_FAIL     = 10030
+
+	ERROR_EXIST_CATEGORY        = 10031
+	ERROR_EXIST_CATEGORY_FAIL   = 10032
+	ERROR_NOT_EXIST_CATEGORY    = 10033
+	ERROR_GET_CATEGORIES_FAIL   = 10034
+	ERROR_ADD_CATEGORY_FAIL     = 10035
+	ERROR_EDIT_CATEGORY_FAIL    = 10036
+	ERROR_DELETE_CATEGORY_FAIL  = 10037
+	ERROR_MOVE_CATEGORY_FAIL    = 10038
+	ERROR_MOVE_CATEGORY_CYCLE   = 10039
+	ERROR_CATEGORY_HAS_CHILDREN = 10040
+
+	ERROR_RENDER_ARTICLE_FAIL = 10041
+
+	ERROR_NOT_EXIST_ARTICLE_REVISION        = 10042
+	ERROR_CHECK_EXIST_ARTICLE_REVISION_FAIL = 10043
+	ERROR_GET_ARTICLE_REVISIONS_FAIL        = 10044
+	ERROR_RESTORE_ARTICLE_REVISION_FAIL     = 10045
+	ERROR_INVALID_PUBLISH_AT                = 10046
+	ERROR_ARTICLE_REVISION_DIFF_TOO_LARGE   = 10047
+	ERROR_COMMENT_ARTICLE_NOT_PUBLISHED     = 10048
+	ERROR_PARENT_COMMENT_NOT_APPROVED       = 10049
+
 	ERROR_AUTH_CHECK_TOKEN_FAIL    = 20001
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT = 20002
 	ERROR_AUTH_TOKEN               = 20003
 	ERROR_AUTH                     = 20004
+	ERROR_EXIST_USER               = 20005
+	ERROR_CHECK_EXIST_USER_FAIL    = 20006
+	ERROR_ADD_USER_FAIL            = 20007
+	ERROR_CHANGE_PASSWORD_FAIL     = 20008
+	ERROR_AUTH_PERMISSION_DENIED   = 20009
+	ERROR_NOT_EXIST_USER           = 20010
+	ERROR_SET_USER_ROLE_FAIL       = 20011
+	ERROR_AUTH_TOKEN_REVOKED       = 20012
+	ERROR_AUTH_LOGOUT_FAIL         = 20013
+	ERROR_AUTH_LOCKED              = 20014
 
 	ERROR_UPLOAD_SAVE_IMAGE_FAIL    = 30001
 	ERROR_UPLOAD_CHECK_IMAGE_FAIL   = 30002
 	ERROR_UPLOAD_CHECK_IMAGE_FORMAT = 30003
+
+	ERROR_NOT_READY         = 40001
+	ERROR_TOO_MANY_REQUESTS = 40002
 )
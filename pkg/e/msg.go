@@ -1,35 +1,77 @@
 package e
 
 var MsgFlags = map[int]string{
-	SUCCESS:                         "ok",
-	ERROR:                           "fail",
-	INVALID_PARAMS:                  "请求参数错误",
-	ERROR_EXIST_TAG:                 "已存在该标签名称",
-	ERROR_EXIST_TAG_FAIL:            "获取已存在标签失败",
-	ERROR_NOT_EXIST_TAG:             "该标签不存在",
-	ERROR_GET_TAGS_FAIL:             "获取所有标签失败",
-	ERROR_COUNT_TAG_FAIL:            "统计标签失败",
-	ERROR_ADD_TAG_FAIL:              "新增标签失败",
-	ERROR_EDIT_TAG_FAIL:             "修改标签失败",
-	ERROR_DELETE_TAG_FAIL:           "删除标签失败",
-	ERROR_EXPORT_TAG_FAIL:           "导出标签失败",
-	ERROR_IMPORT_TAG_FAIL:           "导入标签失败",
-	ERROR_NOT_EXIST_ARTICLE:         "该文章不存在",
-	ERROR_ADD_ARTICLE_FAIL:          "新增文章失败",
-	ERROR_DELETE_ARTICLE_FAIL:       "删除文章失败",
-	ERROR_CHECK_EXIST_ARTICLE_FAIL:  "检查文章是否存在失败",
-	ERROR_EDIT_ARTICLE_FAIL:         "修改文章失败",
-	ERROR_COUNT_ARTICLE_FAIL:        "统计文章失败",
-	ERROR_GET_ARTICLES_FAIL:         "获取多个文章失败",
-	ERROR_GET_ARTICLE_FAIL:          "获取单个文章失败",
-	ERROR_GEN_ARTICLE_POSTER_FAIL:   "生成文章海报失败",
-	ERROR_AUTH_CHECK_TOKEN_FAIL:     "Token鉴权失败",
-	ERROR_AUTH_CHECK_TOKEN_TIMEOUT:  "Token已超时",
-	ERROR_AUTH_TOKEN:                "Token生成失败",
-	ERROR_AUTH:                      "Token错误",
-	ERROR_UPLOAD_SAVE_IMAGE_FAIL:    "保存图片失败",
-	ERROR_UPLOAD_CHECK_IMAGE_FAIL:   "检查图片失败",
-	ERROR_UPLOAD_CHECK_IMAGE_FORMAT: "校验图片错误，图片格式或大小有问题",
+	SUCCESS:                                 "ok",
+	ERROR:                                   "fail",
+	INVALID_PARAMS:                          "请求参数错误",
+	ERROR_EXIST_TAG:                         "已存在该标签名称",
+	ERROR_EXIST_TAG_FAIL:                    "获取已存在标签失败",
+	ERROR_NOT_EXIST_TAG:                     "该标签不存在",
+	ERROR_GET_TAGS_FAIL:                     "获取所有标签失败",
+	ERROR_COUNT_TAG_FAIL:                    "统计标签失败",
+	ERROR_ADD_TAG_FAIL:                      "新增标签失败",
+	ERROR_EDIT_TAG_FAIL:                     "修改标签失败",
+	ERROR_DELETE_TAG_FAIL:                   "删除标签失败",
+	ERROR_EXPORT_TAG_FAIL:                   "导出标签失败",
+	ERROR_IMPORT_TAG_FAIL:                   "导入标签失败",
+	ERROR_NOT_EXIST_ARTICLE:                 "该文章不存在",
+	ERROR_ADD_ARTICLE_FAIL:                  "新增文章失败",
+	ERROR_DELETE_ARTICLE_FAIL:               "删除文章失败",
+	ERROR_CHECK_EXIST_ARTICLE_FAIL:          "检查文章是否存在失败",
+	ERROR_EDIT_ARTICLE_FAIL:                 "修改文章失败",
+	ERROR_COUNT_ARTICLE_FAIL:                "统计文章失败",
+	ERROR_GET_ARTICLES_FAIL:                 "获取多个文章失败",
+	ERROR_GET_ARTICLE_FAIL:                  "获取单个文章失败",
+	ERROR_GEN_ARTICLE_POSTER_FAIL:           "生成文章海报失败",
+	ERROR_NOT_EXIST_COMMENT:                 "该评论不存在",
+	ERROR_CHECK_EXIST_COMMENT_FAIL:          "检查评论是否存在失败",
+	ERROR_ADD_COMMENT_FAIL:                  "新增评论失败",
+	ERROR_EDIT_COMMENT_FAIL:                 "修改评论失败",
+	ERROR_DELETE_COMMENT_FAIL:               "删除评论失败",
+	ERROR_COUNT_COMMENT_FAIL:                "统计评论失败",
+	ERROR_GET_COMMENTS_FAIL:                 "获取评论失败",
+	ERROR_AUDIT_COMMENT_FAIL:                "审核评论失败",
+	ERROR_NOT_EXIST_PARENT_COMMENT:          "回复的评论不存在",
+	ERROR_EXIST_ARTICLE_SLUG:                "已存在该文章别名",
+	ERROR_SEARCH_ARTICLES_FAIL:              "搜索文章失败",
+	ERROR_EXIST_CATEGORY:                    "同级已存在该分类名称",
+	ERROR_EXIST_CATEGORY_FAIL:               "检查分类是否存在失败",
+	ERROR_NOT_EXIST_CATEGORY:                "该分类不存在",
+	ERROR_GET_CATEGORIES_FAIL:               "获取分类失败",
+	ERROR_ADD_CATEGORY_FAIL:                 "新增分类失败",
+	ERROR_EDIT_CATEGORY_FAIL:                "修改分类失败",
+	ERROR_DELETE_CATEGORY_FAIL:              "删除分类失败",
+	ERROR_MOVE_CATEGORY_FAIL:                "移动分类失败",
+	ERROR_MOVE_CATEGORY_CYCLE:               "不能将分类移动到自身或其子分类下",
+	ERROR_CATEGORY_HAS_CHILDREN:             "该分类下还有子分类",
+	ERROR_RENDER_ARTICLE_FAIL:               "渲染文章内容失败",
+	ERROR_NOT_EXIST_ARTICLE_REVISION:        "该文章版本不存在",
+	ERROR_CHECK_EXIST_ARTICLE_REVISION_FAIL: "检查文章版本是否存在失败",
+	ERROR_GET_ARTICLE_REVISIONS_FAIL:        "获取文章版本失败",
+	ERROR_RESTORE_ARTICLE_REVISION_FAIL:     "恢复文章版本失败",
+	ERROR_INVALID_PUBLISH_AT:                "定时发布时间必须晚于当前时间，且只能用于草稿",
+	ERROR_ARTICLE_REVISION_DIFF_TOO_LARGE:   "两个版本之间的差异过大，无法比较",
+	ERROR_COMMENT_ARTICLE_NOT_PUBLISHED:     "文章尚未发布，不能评论",
+	ERROR_PARENT_COMMENT_NOT_APPROVED:       "回复的评论尚未通过审核",
+	ERROR_AUTH_CHECK_TOKEN_FAIL:             "Token鉴权失败",
+	ERROR_AUTH_CHECK_TOKEN_TIMEOUT:          "Token已超时",
+	ERROR_AUTH_TOKEN:                        "Token生成失败",
+	ERROR_AUTH:                              "Token错误",
+	ERROR_EXIST_USER:                        "已存在该用户名",
+	ERROR_CHECK_EXIST_USER_FAIL:             "检查用户是否存在失败",
+	ERROR_ADD_USER_FAIL:                     "注册用户失败",
+	ERROR_CHANGE_PASSWORD_FAIL:              "修改密码失败",
+	ERROR_AUTH_PERMISSION_DENIED:            "没有权限执行该操作",
+	ERROR_NOT_EXIST_USER:                    "该用户不存在",
+	ERROR_SET_USER_ROLE_FAIL:                "修改用户角色失败",
+	ERROR_AUTH_TOKEN_REVOKED:                "Token已失效",
+	ERROR_AUTH_LOGOUT_FAIL:                  "退出登录失败",
+	ERROR_AUTH_LOCKED:                       "登录失败次数过多，账号已被临时锁定",
+	ERROR_UPLOAD_SAVE_IMAGE_FAIL:            "保存图片失败",
+	ERROR_UPLOAD_CHECK_IMAGE_FAIL:           "检查图片失败",
+	ERROR_NOT_READY:                         "服务未就绪",
+	ERROR_TOO_MANY_REQUESTS:                 "请求过于频繁，请稍后再试",
+	ERROR_UPLOAD_CHECK_IMAGE_FORMAT:         "校验图片错误，图片格式或大小有问题",
 }
 
 func GetMsg(code int) string {
@@ -0,0 +1,42 @@
+package graceful
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// 通过环境变量告知子进程继承的文件描述符：
+// 监听 socket 固定为 3，用于通知父进程已就绪的管道为 4
+const (
+	envListenFD = "GINBLOG_LISTEN_FD"
+	envReadyFD  = "GINBLOG_READY_FD"
+)
+
+// Listen 监听 addr；由 Restart 启动的子进程会直接使用从父进程继承的 socket
+func Listen(addr string) (net.Listener, error) {
+	fd, err := strconv.Atoi(os.Getenv(envListenFD))
+	if err != nil || fd <= 0 {
+		return net.Listen("tcp", addr)
+	}
+
+	f := os.NewFile(uintptr(fd), "listener")
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// Ready 在开始接收请求后调用，通知父进程可以退出，不是由 Restart 启动时什么也不做
+func Ready() error {
+	fd, err := strconv.Atoi(os.Getenv(envReadyFD))
+	if err != nil || fd <= 0 {
+		return nil
+	}
+
+	os.Unsetenv(envListenFD)
+	os.Unsetenv(envReadyFD)
+
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package graceful
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// RestartSignal 触发平滑重启的信号
+var RestartSignal os.Signal = syscall.SIGUSR2
+
+// Restart 以相同的参数启动新进程并把监听 socket 交给它，新进程就绪后返回。
+// 返回 nil 后调用方应当停止接收新连接并处理完已有请求再退出
+func Restart(ln net.Listener, timeout time.Duration) error {
+	tl, ok := ln.(*net.TCPListener)
+	if !ok {
+		return errors.New("graceful: listener is not a TCP listener")
+	}
+	lf, err := tl.File()
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	path, err := os.Executable()
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	wd, _ := os.Getwd()
+	process, err := os.StartProcess(path, os.Args, &os.ProcAttr{
+		Dir:   wd,
+		Env:   append(os.Environ(), envListenFD+"=3", envReadyFD+"=4"),
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr, lf, w},
+	})
+	w.Close()
+	if err != nil {
+		return err
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := r.Read(buf)
+		ready <- err
+	}()
+
+	select {
+	case err := <-ready:
+		if err == nil {
+			return nil
+		}
+		process.Kill()
+		return fmt.Errorf("graceful: new process exited before ready: %v", err)
+	case <-time.After(timeout):
+		process.Kill()
+		return fmt.Errorf("graceful: new process not ready after %s", timeout)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package graceful
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// RestartSignal Windows 下不支持平滑重启，为 nil
+var RestartSignal os.Signal
+
+// Restart Windows 下无法把 socket 交给子进程，总是返回错误
+func Restart(ln net.Listener, timeout time.Duration) error {
+	return errors.New("graceful: restart is not supported on windows")
+}
@@ -0,0 +1,166 @@
+package gredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound 由 Fetch 的加载函数返回，表示数据不存在，该结果会被短暂缓存
+var ErrNotFound = errors.New("gredis: not found")
+
+// FetchOptions 缓存的过期策略
+type FetchOptions struct {
+	// Name 为监控指标中的缓存名称，为空时不记录命中率
+	Name string
+	// SoftTTL 过后缓存仍会被返回，同时在后台重新加载
+	SoftTTL time.Duration
+	// HardTTL 为 Redis 键的过期时间，应大于 SoftTTL
+	HardTTL time.Duration
+	// NegativeTTL 为数据不存在时的缓存时间
+	NegativeTTL time.Duration
+	// VersionKey 不为空时，加载前先读取该键的版本，写缓存时版本已变化则放弃写入。
+	// 删除缓存的同时更新版本，可以避免删除前开始的刷新把旧数据写回缓存
+	VersionKey string
+}
+
+// DefaultFetchOptions 默认的过期策略
+var DefaultFetchOptions = FetchOptions{
+	SoftTTL:     10 * time.Minute,
+	HardTTL:     time.Hour,
+	NegativeTTL: time.Minute,
+}
+
+// Named 返回以 name 作为缓存名称的过期策略副本
+func (o FetchOptions) Named(name string) FetchOptions {
+	o.Name = name
+	return o
+}
+
+// Versioned 返回以 key 作为版本键的过期策略副本
+func (o FetchOptions) Versioned(key string) FetchOptions {
+	o.VersionKey = key
+	return o
+}
+
+// fetchEntry Redis 中保存的缓存项
+type fetchEntry struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Missing    bool            `json:"missing,omitempty"`
+	FreshUntil int64           `json:"fresh_until"` // 软过期时间，Unix 毫秒
+}
+
+// 同一个键的并发加载只会执行一次
+var fetchGroup singleflight.Group
+
+// Fetch 读取缓存，未命中时调用 load 加载并写入缓存。
+// 同一进程内对同一个键的并发加载会被合并；缓存超过 SoftTTL 后先返回旧数据，再在后台刷新；
+// load 返回 ErrNotFound 时会缓存不存在的结果，避免反复查询数据库。
+// 每次调用都会得到一份独立解码的数据，调用方可以放心修改
+func Fetch[T any](ctx context.Context, key string, opts FetchOptions, load func() (T, error)) (T, error) {
+	var zero T
+
+	// 未连接 Redis 时直接加载，不经过缓存
+	if RedisClient == nil {
+		return load()
+	}
+
+	data, err := Get(ctx, key)
+	if err != nil && err != redis.Nil {
+		logging.Warn(err)
+	}
+	if err == nil {
+		var entry fetchEntry
+		if err := json.Unmarshal(data, &entry); err == nil {
+			if time.Now().UnixMilli() < entry.FreshUntil {
+				metrics.ObserveCache(opts.Name, metrics.CACHE_HIT)
+			} else {
+				metrics.ObserveCache(opts.Name, metrics.CACHE_STALE)
+				go func() {
+					if _, err, _ := fetchGroup.Do(key, func() (interface{}, error) {
+						return refresh(context.Background(), key, opts, load)
+					}); err != nil {
+						logging.Warn(err)
+					}
+				}()
+			}
+			return decodeEntry[T](&entry)
+		}
+	}
+
+	metrics.ObserveCache(opts.Name, metrics.CACHE_MISS)
+	v, err, _ := fetchGroup.Do(key, func() (interface{}, error) {
+		return refresh(ctx, key, opts, load)
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeEntry[T](v.(*fetchEntry))
+}
+
+// refresh 调用 load 加载数据并写入缓存，写缓存失败只记录日志
+func refresh[T any](ctx context.Context, key string, opts FetchOptions, load func() (T, error)) (*fetchEntry, error) {
+	// 版本必须在加载之前读取，加载期间发生的删除才能被发现
+	var version string
+	if opts.VersionKey != "" {
+		var err error
+		if version, err = GetVersion(ctx, opts.VersionKey); err != nil {
+			logging.Warn(err)
+		}
+	}
+
+	value, err := load()
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	entry := &fetchEntry{Missing: err == ErrNotFound}
+	ttl := opts.NegativeTTL
+	if !entry.Missing {
+		if entry.Data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+		ttl = opts.HardTTL
+	}
+
+	soft := opts.SoftTTL
+	if entry.Missing || soft <= 0 || soft > ttl {
+		soft = ttl
+	}
+	entry.FreshUntil = time.Now().Add(soft).UnixMilli()
+
+	if err := store(ctx, key, opts.VersionKey, version, entry, ttl); err != nil {
+		logging.Warn(err)
+	}
+	return entry, nil
+}
+
+// store 写入缓存项。设置了版本键时只在版本未变化时写入，加载前读取版本失败则不写入
+func store(ctx context.Context, key, versionKey, version string, entry *fetchEntry, ttl time.Duration) error {
+	if versionKey == "" {
+		return Set(ctx, key, entry, ttl)
+	}
+	if version == "" {
+		return nil
+	}
+	_, err := SetIfVersion(ctx, key, versionKey, version, entry, ttl)
+	return err
+}
+
+func decodeEntry[T any](entry *fetchEntry) (T, error) {
+	var value T
+	if entry.Missing {
+		return value, ErrNotFound
+	}
+	if err := json.Unmarshal(entry.Data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
@@ -3,18 +3,26 @@ package gredis
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 	"github.com/redis/go-redis/v9"
 )
 
+// RedisClient 为 nil 时（未调用 SetUp，例如单元测试中）缓存降级：读取视为未命中，
+// 删除和使缓存失效直接成功，需要保存状态的写入返回 ErrNotConnected
 var RedisClient *redis.Client
 
+// ErrNotConnected 未连接 Redis
+var ErrNotConnected = errors.New("gredis: not connected")
+
 func SetUp() error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:            setting.RedisSetting.Addr,
 		Password:        setting.RedisSetting.Password,
+		DB:              setting.RedisSetting.DB,
 		PoolSize:        setting.RedisSetting.MaxActive,
 		MinIdleConns:    setting.RedisSetting.MaxIdle,
 		ConnMaxIdleTime: setting.RedisSetting.IdleTimeout,
@@ -29,7 +37,27 @@ func SetUp() error {
 	return nil
 }
 
+// Ping 检查 Redis 连接是否可用
+func Ping(ctx context.Context) error {
+	if RedisClient == nil {
+		return ErrNotConnected
+	}
+	return RedisClient.Ping(ctx).Err()
+}
+
+// Close 关闭 Redis 连接池
+func Close() error {
+	if RedisClient == nil {
+		return nil
+	}
+	return RedisClient.Close()
+}
+
 func Set(ctx context.Context, key string, data interface{}, expiration time.Duration) error {
+	if RedisClient == nil {
+		return ErrNotConnected
+	}
+
 	value, err := json.Marshal(data)
 	if err != nil {
 		return err
@@ -42,7 +70,51 @@ func Set(ctx context.Context, key string, data interface{}, expiration time.Dura
 	return nil
 }
 
+// SetNX 仅在键不存在时写入，返回是否写入成功，可用于原子地占用一个键
+func SetNX(ctx context.Context, key string, data interface{}, expiration time.Duration) (bool, error) {
+	if RedisClient == nil {
+		return false, ErrNotConnected
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+	return RedisClient.SetNX(ctx, key, value, expiration).Result()
+}
+
+// setIfVersionScript 版本键的值与 ARGV[1] 相同时才写入，版本键不存在时视为 "0"，与 GetVersion 一致
+var setIfVersionScript = redis.NewScript(`
+if (redis.call('GET', KEYS[2]) or '0') ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// SetIfVersion 仅在 versionKey 的当前版本仍为 version 时写入，返回是否写入成功。
+// 比较和写入在同一个脚本中原子执行
+func SetIfVersion(ctx context.Context, key, versionKey, version string, data interface{}, expiration time.Duration) (bool, error) {
+	if RedisClient == nil {
+		return false, ErrNotConnected
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+	n, err := setIfVersionScript.Run(ctx, RedisClient, []string{key, versionKey}, version, value, expiration.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
 func Exists(ctx context.Context, key string) (bool, error) {
+	if RedisClient == nil {
+		return false, nil
+	}
+
 	exists, err := RedisClient.Exists(ctx, key).Result()
 	if err != nil {
 		return false, err
@@ -51,6 +123,10 @@ func Exists(ctx context.Context, key string) (bool, error) {
 }
 
 func Get(ctx context.Context, key string) ([]byte, error) {
+	if RedisClient == nil {
+		return nil, redis.Nil
+	}
+
 	value, err := RedisClient.Get(ctx, key).Bytes()
 	if err != nil {
 		return nil, err
@@ -59,6 +135,10 @@ func Get(ctx context.Context, key string) ([]byte, error) {
 }
 
 func Delete(ctx context.Context, key string) error {
+	if RedisClient == nil {
+		return nil
+	}
+
 	err := RedisClient.Del(ctx, key).Err()
 	if err != nil {
 		return err
@@ -66,17 +146,83 @@ func Delete(ctx context.Context, key string) error {
 	return nil
 }
 
-func LikeDeletes(ctx context.Context, pattern string) error {
-	keys, err := RedisClient.Keys(ctx, "*"+pattern+"*").Result()
+// Deletes 一次删除多个键
+func Deletes(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 || RedisClient == nil {
+		return nil
+	}
+	return RedisClient.Del(ctx, keys...).Err()
+}
+
+// GetVersion 获取缓存命名空间的当前版本，未设置时为 "0"。
+// 版本号作为缓存键的一部分，版本变化后旧键不会再被读取，等待 TTL 自然过期
+func GetVersion(ctx context.Context, key string) (string, error) {
+	if RedisClient == nil {
+		return "0", nil
+	}
+
+	version, err := RedisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "0", nil
+	}
 	if err != nil {
-		return err
+		return "", err
+	}
+	return version, nil
+}
+
+// BumpVersion 使缓存命名空间的版本变化，从而让该命名空间下的所有缓存失效。
+// 版本取当前纳秒时间戳，即使版本键丢失也不会与仍未过期的旧键重复
+func BumpVersion(ctx context.Context, key string) error {
+	if RedisClient == nil {
+		return nil
+	}
+	return RedisClient.Set(ctx, key, strconv.FormatInt(time.Now().UnixNano(), 10), 0).Err()
+}
+
+// GetInt64 获取整数值，键不存在时返回 0
+func GetInt64(ctx context.Context, key string) (int64, error) {
+	if RedisClient == nil {
+		return 0, nil
 	}
 
-	if len(keys) > 0 {
-		err = RedisClient.Del(ctx, keys...).Err()
-		if err != nil {
-			return err
+	n, err := RedisClient.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Incr 将计数加一，计数为新建时设置过期时间，返回加一后的值
+func Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	if RedisClient == nil {
+		return 0, ErrNotConnected
+	}
+
+	n, err := RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 && expiration > 0 {
+		if err := RedisClient.Expire(ctx, key, expiration).Err(); err != nil {
+			return n, err
 		}
 	}
-	return nil
+	return n, nil
+}
+
+// TTL 获取键的剩余过期时间，键不存在或没有过期时间时返回 0
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	if RedisClient == nil {
+		return 0, nil
+	}
+
+	ttl, err := RedisClient.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
 }
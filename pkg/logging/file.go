@@ -3,6 +3,10 @@ package logging
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/3Eeeecho/go-gin-example/pkg/file"
@@ -13,12 +17,14 @@ func getLogFilePath() string {
 	return setting.AppSetting.RuntimeRootPath + setting.AppSetting.LogSavePath
 }
 
-func getLogFileName() string {
-	return fmt.Sprintf("%s%s.%s",
-		setting.AppSetting.LogSaveName,
-		time.Now().Format(setting.AppSetting.TimeFormat),
-		setting.AppSetting.LogFileExt,
-	)
+// getLogFileName 返回某个周期内第 index 个日志文件的名称，
+// 例如 log20060102.log、log20060102.1.log
+func getLogFileName(period string, index int) string {
+	name := setting.AppSetting.LogSaveName + period
+	if index > 0 {
+		name += "." + strconv.Itoa(index)
+	}
+	return name + "." + setting.AppSetting.LogFileExt
 }
 
 func openLogFile(filePath, fileName string) (*os.File, error) {
@@ -46,3 +52,130 @@ func openLogFile(filePath, fileName string) (*os.File, error) {
 
 	return f, nil
 }
+
+// rotateWriter 按时间和大小切分的日志文件。
+// 每个 TimeFormat 周期使用一个新文件，单个文件超过 maxSize 时在同一周期内追加序号，
+// 每次切分后删除修改时间早于 maxAge 的旧文件
+type rotateWriter struct {
+	mu      sync.Mutex
+	maxSize int64
+	maxAge  time.Duration
+
+	f      *os.File
+	period string
+	index  int
+	size   int64
+}
+
+func newRotateWriter(maxSize int64, maxAge time.Duration) (*rotateWriter, error) {
+	w := &rotateWriter{maxSize: maxSize, maxAge: maxAge}
+	if err := w.open(w.currentPeriod(), w.lastIndex(w.currentPeriod())); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	period := w.currentPeriod()
+	switch {
+	case period != w.period:
+		if err := w.rotate(period, 0); err != nil {
+			return 0, err
+		}
+	case w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize:
+		if err := w.rotate(period, w.index+1); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Sync()
+}
+
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.f.Sync(); err != nil {
+		return err
+	}
+	return w.f.Close()
+}
+
+func (w *rotateWriter) currentPeriod() string {
+	return time.Now().Format(setting.AppSetting.TimeFormat)
+}
+
+// lastIndex 找到周期内已存在的最大序号，重启后继续写入最新的文件
+func (w *rotateWriter) lastIndex(period string) int {
+	index := 0
+	for {
+		if _, err := os.Stat(getLogFilePath() + getLogFileName(period, index+1)); err != nil {
+			return index
+		}
+		index++
+	}
+}
+
+func (w *rotateWriter) open(period string, index int) error {
+	f, err := openLogFile(getLogFilePath(), getLogFileName(period, index))
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.f, w.period, w.index, w.size = f, period, index, info.Size()
+	return nil
+}
+
+func (w *rotateWriter) rotate(period string, index int) error {
+	old := w.f
+	if err := w.open(period, index); err != nil {
+		return err
+	}
+	old.Close()
+
+	go w.removeExpired()
+	return nil
+}
+
+// removeExpired 删除超过保留时间的日志文件
+func (w *rotateWriter) removeExpired() {
+	if w.maxAge <= 0 {
+		return
+	}
+
+	dir := getLogFilePath()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(-w.maxAge)
+	prefix, ext := setting.AppSetting.LogSaveName, "."+setting.AppSetting.LogFileExt
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(deadline) {
+			continue
+		}
+		os.Remove(filepath.Join(dir, name))
+	}
+}
@@ -1,80 +1,196 @@
 package logging
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 )
 
-type Level int
+// LevelFatal 比 slog.LevelError 更高的级别，输出为 FATAL
+const LevelFatal = slog.Level(12)
 
+// 请求相关的日志字段
 const (
-	DEBUG Level = iota
-	INFO
-	WARNING
-	ERROR
-	FATAL
+	KeyRequestID = "request_id"
+	KeyUser      = "user"
+	KeyRoute     = "route"
 )
 
 var (
-	F *os.File
-
-	DefaultPrefix      = ""
-	DefaultCallerDepth = 2
-	logger             *log.Logger
-	logPrefix          = ""
-	levelFlags         = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+	writer io.WriteCloser = nopCloser{os.Stderr}
+	level                 = new(slog.LevelVar)
+	logger                = slog.New(newHandler(os.Stderr, "text"))
 )
 
 func SetUp() {
-
-	filepath := getLogFilePath()
-	fileName := getLogFileName()
-	F, err := openLogFile(filepath, fileName)
+	w, err := newRotateWriter(int64(setting.AppSetting.LogMaxSize), setting.AppSetting.LogMaxAge)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	logger = log.New(F, DefaultPrefix, log.LstdFlags)
+	if err := SetLevel(setting.AppSetting.LogLevel); err != nil {
+		log.Fatalln(err)
+	}
+
+	writer = w
+	logger = slog.New(newHandler(w, setting.AppSetting.LogFormat))
+	slog.SetDefault(logger)
+
+	setting.Subscribe(func(old, new *setting.Config) {
+		if old.App.LogLevel == new.App.LogLevel {
+			return
+		}
+		if err := SetLevel(new.App.LogLevel); err != nil {
+			Warn(err)
+		}
+	})
+}
+
+// Close 将日志写入磁盘并关闭日志文件
+func Close() error {
+	return writer.Close()
+}
+
+// SetLevel 设置输出的最低级别，可选 debug、info、warn、error，为空时使用 info
+func SetLevel(s string) error {
+	var l slog.Level
+	switch strings.ToLower(s) {
+	case "debug":
+		l = slog.LevelDebug
+	case "", "info":
+		l = slog.LevelInfo
+	case "warn", "warning":
+		l = slog.LevelWarn
+	case "error":
+		l = slog.LevelError
+	default:
+		return fmt.Errorf("logging: unknown level %q", s)
+	}
+	level.Set(l)
+	return nil
+}
+
+// Logger 返回全局的结构化日志
+func Logger() *slog.Logger {
+	return logger
+}
+
+// NewContext 返回附加了日志字段的 context，之后通过 FromContext 记录的日志都会带上这些字段
+func NewContext(ctx context.Context, args ...any) context.Context {
+	attrs := append(attrsFromContext(ctx), argsToAttrs(args)...)
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+// FromContext 返回带有 ctx 中日志字段的日志
+func FromContext(ctx context.Context) *slog.Logger {
+	attrs := attrsFromContext(ctx)
+	if len(attrs) == 0 {
+		return logger
+	}
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return logger.With(args...)
 }
 
 func Debug(v ...interface{}) {
-	setPrefix(DEBUG)
-	logger.Println(v...)
-	F.Sync()
+	output(slog.LevelDebug, v)
 }
 
 func Info(v ...interface{}) {
-	setPrefix(INFO)
-	logger.Println(v...)
-	F.Sync()
+	output(slog.LevelInfo, v)
 }
 
 func Warn(v ...interface{}) {
-	setPrefix(WARNING)
-	logger.Println(v...)
-	F.Sync()
+	output(slog.LevelWarn, v)
 }
 
 func Error(v ...interface{}) {
-	setPrefix(ERROR)
-	logger.Println(v...)
-	F.Sync()
+	output(slog.LevelError, v)
 }
 
 func Fatal(v ...interface{}) {
-	setPrefix(FATAL)
-	logger.Println(v...)
-	F.Sync()
+	output(LevelFatal, v)
 }
 
-func setPrefix(level Level) {
-	_, file, line, ok := runtime.Caller(DefaultCallerDepth)
-	if ok {
-		logPrefix = fmt.Sprintf("[%s][%s:%d]", levelFlags[level], filepath.Base(file), line)
-	} else {
-		logPrefix = fmt.Sprintf("[%s]", levelFlags[level])
+// output 兼容旧的 Println 风格接口，记录调用方的位置
+func output(l slog.Level, v []interface{}) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, l) {
+		return
 	}
-	logger.SetPrefix(logPrefix)
+
+	var pcs [1]uintptr
+	// 跳过 runtime.Callers、output 和 Info 等导出函数
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), l, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), pcs[0])
+	logger.Handler().Handle(ctx, r)
+}
+
+func newHandler(w io.Writer, format string) slog.Handler {
+	opts := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}
+
+	if strings.ToLower(format) == "text" {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// replaceAttr 输出 FATAL 级别名称，并把源码位置缩短为 file.go:line
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok && l >= LevelFatal {
+			a.Value = slog.StringValue("FATAL")
+		}
+	case slog.SourceKey:
+		if s, ok := a.Value.Any().(*slog.Source); ok {
+			a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(s.File), s.Line))
+		}
+	}
+	return a
 }
+
+type ctxKey struct{}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	// 复制一份，避免多个 context 共用底层数组
+	return append([]slog.Attr(nil), attrs...)
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
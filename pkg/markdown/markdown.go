@@ -0,0 +1,112 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// TocItem 目录中的一个标题，Children 为其下一级标题
+type TocItem struct {
+	Level    int        `json:"level"`
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Children []*TocItem `json:"children,omitempty"`
+}
+
+// Result 渲染结果
+type Result struct {
+	HTML string     `json:"html"`
+	Toc  []*TocItem `json:"toc"`
+}
+
+var (
+	md = goldmark.New(
+		// GFM：表格、删除线、自动链接、任务列表
+		goldmark.WithExtensions(extension.GFM),
+		// 为标题自动生成锚点 id
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	policy = newPolicy()
+)
+
+// newPolicy 在 UGC 策略的基础上允许代码高亮所需的 class 和标题锚点 id
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\w-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("type", "checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render 将 markdown 渲染为经过过滤的 HTML，并根据标题生成目录
+func Render(source string) (*Result, error) {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		HTML: policy.Sanitize(buf.String()),
+		Toc:  buildToc(doc, src),
+	}, nil
+}
+
+// buildToc 遍历文档中的标题，按级别组装成嵌套目录
+func buildToc(doc ast.Node, src []byte) []*TocItem {
+	var (
+		toc   []*TocItem
+		stack []*TocItem
+	)
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		item := &TocItem{Level: heading.Level, Title: headingText(heading, src)}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.ID = string(b)
+			}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			toc = append(toc, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return toc
+}
+
+func headingText(n ast.Node, src []byte) string {
+	var buf bytes.Buffer
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			buf.Write(t.Segment.Value(src))
+			continue
+		}
+		buf.WriteString(headingText(c, src))
+	}
+	return buf.String()
+}
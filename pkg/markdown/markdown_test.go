@@ -0,0 +1,105 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, source string) *Result {
+	t.Helper()
+
+	result, err := Render(source)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return result
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	result := render(t, strings.Join([]string{
+		"<script>alert(1)</script>",
+		"",
+		`<a href="#" onclick="steal()">link</a> <img src="a.png" onerror="steal()">`,
+		"",
+		"[bad](javascript:alert(1))",
+	}, "\n"))
+
+	for _, unsafe := range []string{"<script", "alert(1)", "onclick", "onerror", "javascript:"} {
+		if strings.Contains(result.HTML, unsafe) {
+			t.Errorf("rendered HTML contains %q: %s", unsafe, result.HTML)
+		}
+	}
+
+	// 渲染器放开原始 HTML 时仍由过滤策略兜底
+	html := policy.Sanitize(`<p onmouseover="steal()">text</p><script>alert(1)</script><a href="/a" onclick="steal()">a</a>`)
+	if html != `<p>text</p><a href="/a" rel="nofollow">a</a>` {
+		t.Errorf("Sanitize = %s", html)
+	}
+}
+
+func TestRenderKeepsCodeClassAndHeadingID(t *testing.T) {
+	result := render(t, "## Install\n\n```go\nfmt.Println()\n```\n\n```c++\nint x;\n```\n\n- [x] done\n")
+
+	for _, want := range []string{
+		`<h2 id="install">Install</h2>`,
+		`<code class="language-go">`,
+		`<code class="language-c++">`,
+		`<input checked="" disabled="" type="checkbox">`,
+	} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("rendered HTML does not contain %s: %s", want, result.HTML)
+		}
+	}
+
+	// 不是代码高亮的 class 会被去掉
+	if html := policy.Sanitize(`<code class="evil">x</code>`); html != "<code>x</code>" {
+		t.Errorf("Sanitize(code class) = %s", html)
+	}
+}
+
+var headingID = regexp.MustCompile(`<h[1-6] id="([^"]*)">`)
+
+func TestTocMatchesHeadingIDs(t *testing.T) {
+	result := render(t, strings.Join([]string{
+		"# 介绍",
+		"## 安装",
+		"## Hello World",
+		"### 使用 Gin",
+		"## Hello World",
+		"# 总结",
+	}, "\n\n"))
+
+	var flatten func(items []*TocItem) []*TocItem
+	flatten = func(items []*TocItem) []*TocItem {
+		var all []*TocItem
+		for _, item := range items {
+			all = append(all, item)
+			all = append(all, flatten(item.Children)...)
+		}
+		return all
+	}
+	items := flatten(result.Toc)
+
+	var htmlIDs []string
+	for _, m := range headingID.FindAllStringSubmatch(result.HTML, -1) {
+		htmlIDs = append(htmlIDs, m[1])
+	}
+
+	// 中文标题没有可用的字符，依次生成 heading、heading-1……
+	wantIDs := []string{"heading", "heading-1", "hello-world", "-gin", "hello-world-1", "heading-2"}
+	wantTitles := []string{"介绍", "安装", "Hello World", "使用 Gin", "Hello World", "总结"}
+	if len(items) != len(wantIDs) || len(htmlIDs) != len(wantIDs) {
+		t.Fatalf("toc has %d items and HTML has %d heading ids, want %d", len(items), len(htmlIDs), len(wantIDs))
+	}
+	for i, item := range items {
+		if item.ID != htmlIDs[i] || item.ID != wantIDs[i] || item.Title != wantTitles[i] {
+			t.Errorf("toc item %d = %q (%s), HTML id %q, want %q (%s)", i, item.ID, item.Title, htmlIDs[i], wantIDs[i], wantTitles[i])
+		}
+	}
+
+	// 目录按级别嵌套
+	if len(result.Toc) != 2 || len(result.Toc[0].Children) != 3 || len(result.Toc[0].Children[1].Children) != 1 {
+		t.Errorf("toc structure = %d roots, first with %d children, want 2 roots and 3 children", len(result.Toc), len(result.Toc[0].Children))
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "ginblog"
+
+var (
+	// HTTPRequests 按路由、方法和状态码统计的请求数
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration 按路由和方法统计的请求耗时
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration 按操作类型和表统计的数据库查询耗时
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// CacheRequests 按缓存名称统计的读取结果，result 为 hit、stale 或 miss
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache name and result (hit, stale, miss).",
+	}, []string{"cache", "result"})
+
+	// CronRuns 按任务和结果统计的定时任务执行次数，result 为 success 或 failure
+	CronRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cron_runs_total",
+		Help:      "Cron job runs by job and result (success, failure).",
+	}, []string{"job", "result"})
+
+	// CronDuration 按任务统计的定时任务耗时
+	CronDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "cron_duration_seconds",
+		Help:      "Cron job duration by job.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+)
+
+// 缓存读取结果
+const (
+	CACHE_HIT   = "hit"
+	CACHE_STALE = "stale"
+	CACHE_MISS  = "miss"
+)
+
+// ObserveCache 记录一次缓存读取，name 为空时不记录
+func ObserveCache(name, result string) {
+	if name == "" {
+		return
+	}
+	CacheRequests.WithLabelValues(name, result).Inc()
+}
+
+// CronJob 包装定时任务，记录执行次数、失败次数和耗时
+func CronJob(name string, job func() error) func() error {
+	return func() error {
+		start := time.Now()
+		err := job()
+		CronDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		CronRuns.WithLabelValues(name, result).Inc()
+		return err
+	}
+}
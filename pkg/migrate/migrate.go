@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Migration 一次数据库结构变更，按 ID 的字典序执行，ID 一经发布不能修改
+type Migration struct {
+	ID   string
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error // 为 nil 表示不可回滚
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	ID        string
+	Applied   bool
+	AppliedOn int // 执行时间，Unix 秒
+}
+
+// record 迁移历史表中的一行
+type record struct {
+	ID        string `gorm:"column:id"`
+	AppliedOn int    `gorm:"column:applied_on"`
+}
+
+// Migrator 在 table 表中记录已执行的迁移
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []Migration
+}
+
+func New(db *gorm.DB, table string, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return &Migrator{db: db, table: table, migrations: sorted}
+}
+
+// Up 依次执行所有未执行的迁移，返回本次执行的迁移ID。
+// 每个迁移在单独的事务中执行，失败时停止，之前成功的迁移会保留。
+// 注意 MySQL 的 DDL 会隐式提交事务，DDL 失败时需要手动处理已部分完成的变更
+func (m *Migrator) Up() ([]string, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.ID]; ok {
+			continue
+		}
+
+		err := m.run(migration.ID, migration.Up, func(tx *gorm.DB) error {
+			return tx.Exec("INSERT INTO "+m.table+" (id, applied_on) VALUES (?, ?)", migration.ID, time.Now().Unix()).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration.ID)
+	}
+	return done, nil
+}
+
+// Down 按执行顺序倒序回滚最近的 steps 个迁移，返回本次回滚的迁移ID
+func (m *Migrator) Down(steps int) ([]string, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.ID]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("migrate: %s is irreversible", migration.ID)
+		}
+
+		err := m.run(migration.ID, migration.Down, func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM "+m.table+" WHERE id = ?", migration.ID).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration.ID)
+	}
+	return done, nil
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedOn, ok := applied[migration.ID]
+		statuses = append(statuses, Status{ID: migration.ID, Applied: ok, AppliedOn: appliedOn})
+	}
+	return statuses, nil
+}
+
+// run 在事务中执行迁移并更新历史表
+func (m *Migrator) run(id string, migrate, record func(tx *gorm.DB) error) error {
+	tx := m.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	if err := migrate(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migrate: %s: %v", id, err)
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migrate: %s: %v", id, err)
+	}
+	return tx.Commit().Error
+}
+
+// applied 返回已执行的迁移及其执行时间，历史表不存在时先创建
+func (m *Migrator) applied() (map[string]int, error) {
+	err := m.db.Exec("CREATE TABLE IF NOT EXISTS " + m.table + " (" +
+		"id VARCHAR(191) NOT NULL PRIMARY KEY, " +
+		"applied_on INTEGER NOT NULL DEFAULT 0)").Error
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	if err := m.db.Table(m.table).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]int, len(records))
+	for _, r := range records {
+		applied[r.ID] = r.AppliedOn
+	}
+	return applied, nil
+}
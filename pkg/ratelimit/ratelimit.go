@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/redis/go-redis/v9"
+)
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // 被拒绝时距离下一次允许请求的时间
+}
+
+// 滑动窗口日志：有序集合中保存窗口内每次请求的时间，
+// 先清理窗口外的记录，未达到上限时记录本次请求，否则根据最早的记录计算重试时间
+var allowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// Allow 判断 key 在滑动窗口内是否还允许一次请求，允许时计入本次请求
+func Allow(ctx context.Context, key string, rate setting.Rate) (*Result, error) {
+	if rate.Limit <= 0 {
+		return &Result{Allowed: true}, nil
+	}
+	if gredis.RedisClient == nil {
+		return nil, gredis.ErrNotConnected
+	}
+
+	now := time.Now().UnixMilli()
+	values, err := allowScript.Run(ctx, gredis.RedisClient, []string{key},
+		now, rate.Window.Milliseconds(), rate.Limit, member(now)).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// member 有序集合的成员需要唯一，同一毫秒内的多次请求才能分别计数
+func member(now int64) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return strconv.FormatInt(now, 10) + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,46 @@
+package setting
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 打印配置时隐藏的配置项
+var secretKeys = map[string]bool{
+	"JwtSecret": true,
+	"Password":  true,
+}
+
+// Print 以 ini 格式输出合并后的有效配置，敏感项显示为 ******，时长显示为 Go 的格式（如 30m0s），大小为字节数
+func (c *Config) Print(w io.Writer) {
+	for i, sec := range c.sections() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s]\n", sec.name)
+
+		val := reflect.ValueOf(sec.value).Elem()
+		for j := 0; j < val.NumField(); j++ {
+			name := val.Type().Field(j).Name
+			fmt.Fprintf(w, "%s = %s\n", name, formatValue(name, val.Field(j)))
+		}
+	}
+}
+
+func formatValue(name string, v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case []string:
+		return strings.Join(value, ",")
+	case string:
+		if secretKeys[name] && value != "" {
+			return "******"
+		}
+		return value
+	}
+	return fmt.Sprint(v.Interface())
+}
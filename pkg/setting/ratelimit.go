@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate 时间窗口内允许的最大请求次数
+type Rate struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRate 解析 次数/时间窗口 格式的规则，如 10/1m、100/30s。
+// 时间窗口省略数字时表示 1 个单位，如 10/m；空字符串返回零值，表示不限流
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Rate{}, nil
+	}
+
+	limit, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q, want <limit>/<window>", s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("invalid limit in rate %q", s)
+	}
+
+	window = strings.TrimSpace(window)
+	if window != "" && (window[0] < '0' || window[0] > '9') {
+		window = "1" + window
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("invalid window in rate %q", s)
+	}
+
+	return Rate{Limit: n, Window: d}, nil
+}
+
+// Rule 返回分组的限流规则和限流维度
+func (r *RateLimit) Rule(group string) (rule, key string) {
+	switch group {
+	case "auth":
+		return r.Auth, r.AuthKey
+	case "public":
+		return r.Public, r.PublicKey
+	case "api":
+		return r.Api, r.ApiKey
+	}
+	return "", ""
+}
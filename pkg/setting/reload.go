@@ -0,0 +1,116 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	current atomic.Pointer[Config]
+
+	// SetUp 时使用的配置文件，热更新时重新读取
+	configPath     string
+	configExplicit bool
+
+	reloadMu    sync.Mutex
+	subscribers []func(old, new *Config)
+)
+
+// Current 返回当前的配置快照。快照不会被修改，热更新时整体替换，
+// 需要热更新的配置项应当每次使用时通过 Current 读取，而不是读取 AppSetting 等全局配置
+func Current() *Config {
+	return current.Load()
+}
+
+// Subscribe 注册配置热更新后的回调，回调在 Reload 中同步执行
+func Subscribe(fn func(old, new *Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Reload 重新读取配置文件和环境变量，校验通过后替换配置快照并通知订阅者，返回发生变化的配置项。
+// 修改了不支持热更新的配置项（如数据库地址）时拒绝整个更新，需要重启进程
+func Reload() ([]string, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, _, err := load(configPath, configExplicit)
+	if err != nil {
+		return nil, err
+	}
+
+	old := Current()
+	changed, static := diff(old, cfg)
+	if len(static) > 0 {
+		return nil, fmt.Errorf("setting: refuse to reload, restart is required to change %s", strings.Join(static, ", "))
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	current.Store(cfg)
+	for _, fn := range subscribers {
+		fn(old, cfg)
+	}
+	return changed, nil
+}
+
+// diff 比较两份配置，changed 为变化的可热更新配置项，static 为变化的不可热更新配置项
+func diff(old, new *Config) (changed, static []string) {
+	oldSections, newSections := old.sections(), new.sections()
+	for i, sec := range newSections {
+		oldVal := reflect.ValueOf(oldSections[i].value).Elem()
+		newVal := reflect.ValueOf(sec.value).Elem()
+		for j := 0; j < newVal.NumField(); j++ {
+			if reflect.DeepEqual(oldVal.Field(j).Interface(), newVal.Field(j).Interface()) {
+				continue
+			}
+
+			field := newVal.Type().Field(j)
+			key := "[" + sec.name + "] " + field.Name
+			if field.Tag.Get("reload") == "true" {
+				changed = append(changed, key)
+			} else {
+				static = append(static, key)
+			}
+		}
+	}
+	return changed, static
+}
+
+// Watch 定期检查配置文件的修改时间，变化后调用 Reload，直到 ctx 结束。
+// 采用轮询而不是文件系统通知，编辑器以替换文件的方式保存时也能发现变化
+func Watch(ctx context.Context, interval time.Duration, onReload func(changed []string, err error)) {
+	modTime := func() time.Time {
+		info, err := os.Stat(configPath)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+
+	last := modTime()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if t := modTime(); !t.Equal(last) {
+			last = t
+			changed, err := Reload()
+			onReload(changed, err)
+		}
+	}
+}
@@ -1,30 +1,44 @@
 package setting
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/go-ini/ini"
 )
 
+// App 应用配置，标记了 reload 的配置项可以热更新，需要通过 Current 读取才能获得更新后的值
 type App struct {
-	JwtSecret string
-	PageSize  int
-	PrefixUrl string
+	JwtSecret        string
+	JwtAccessExpire  time.Duration
+	JwtRefreshExpire time.Duration
+	PageSize         int `reload:"true"`
+	PrefixUrl        string
 
 	RuntimeRootPath string
 
 	ImageSavePath  string
-	ImageMaxSize   int
-	ImageAllowExts []string
+	ImageMaxSize   int      `reload:"true"`
+	ImageAllowExts []string `reload:"true"`
 
 	LogSavePath string
 	LogSaveName string
 	LogFileExt  string
 	TimeFormat  string
+	LogLevel    string `reload:"true"`
+	LogFormat   string
+	LogMaxSize  int
+	LogMaxAge   time.Duration
 
 	ExportSavePath string
 	QrCodeSavePath string
+
+	ViewDedupWindow time.Duration `reload:"true"`
+
+	SearchEngine string
 }
 
 var AppSetting = &App{}
@@ -34,17 +48,33 @@ type Server struct {
 	HttpPort     int
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+
+	ShutdownTimeout time.Duration
+	GracefulRestart bool
+	// TrustedProxies 可信反向代理的 IP 或 CIDR，只有来自这些地址的请求才会使用
+	// X-Forwarded-For / X-Real-IP 确定客户端 IP。为空时不信任任何代理，直接使用连接的对端地址
+	TrustedProxies []string
 }
 
 var ServerSetting = &Server{}
 
+// 支持的数据库类型
+const (
+	DB_MYSQL    = "mysql"
+	DB_POSTGRES = "postgres"
+	DB_SQLITE   = "sqlite3"
+)
+
 type Database struct {
+	// Type 数据库类型，mysql、postgres 或 sqlite3，也接受 postgresql、sqlite 等别名
 	Type        string
 	User        string
 	Password    string
 	Host        string
 	Name        string
 	TablePrefix string
+	// AutoMigrate 启动时自动执行未执行的数据库迁移，多实例部署时建议关闭并使用 migrate 子命令
+	AutoMigrate bool
 }
 
 var DatabaseSetting = &Database{}
@@ -52,6 +82,7 @@ var DatabaseSetting = &Database{}
 type Redis struct {
 	Addr        string
 	Password    string
+	DB          int
 	MaxActive   int
 	MaxIdle     int
 	IdleTimeout time.Duration
@@ -59,29 +90,224 @@ type Redis struct {
 
 var RedisSetting = &Redis{}
 
+// RateLimit 限流配置，全部可以热更新
+type RateLimit struct {
+	Enabled bool `reload:"true"`
+
+	// 各分组的限流规则，格式为 次数/时间窗口（如 10/1m），为空时不限流
+	Auth   string `reload:"true"`
+	Public string `reload:"true"`
+	Api    string `reload:"true"`
+	// 各分组的限流维度：ip、user 或 token
+	AuthKey   string `reload:"true"`
+	PublicKey string `reload:"true"`
+	ApiKey    string `reload:"true"`
+
+	LoginMaxFailures   int           `reload:"true"`
+	LoginFailureWindow time.Duration `reload:"true"`
+	LoginLockout       time.Duration `reload:"true"`
+}
+
+var RateLimitSetting = &RateLimit{}
+
+// Config 一份完整的配置，每个字段对应 ini 中的一个分组
+type Config struct {
+	App       App
+	Server    Server
+	Database  Database
+	Redis     Redis
+	RateLimit RateLimit
+}
+
+// DefaultConfigPath 未通过参数或 GINBLOG_CONFIG 指定配置文件时读取的路径
+const DefaultConfigPath = "conf/app.ini"
+
 var Cfg *ini.File
 
-func SetUp() {
-	var err error
-	Cfg, err = ini.Load("conf/app.ini")
+// SetUp 加载配置并写入各个全局配置，path 为空时依次使用 GINBLOG_CONFIG 和 DefaultConfigPath。
+// 全局配置保存启动时的值，热更新只替换 Current 返回的快照
+func SetUp(path string) error {
+	path, explicit := resolvePath(path)
+	cfg, f, err := load(path, explicit)
 	if err != nil {
-		log.Fatalf("Fail to parse 'conf/app.ini': %v", err)
+		return err
 	}
 
-	mapTo("app", AppSetting)
-	mapTo("server", ServerSetting)
-	mapTo("database", DatabaseSetting)
-	mapTo("redis", RedisSetting)
+	Cfg = f
+	configPath, configExplicit = path, explicit
+	current.Store(cfg)
+	*AppSetting = cfg.App
+	*ServerSetting = cfg.Server
+	*DatabaseSetting = cfg.Database
+	*RedisSetting = cfg.Redis
+	*RateLimitSetting = cfg.RateLimit
+	return nil
+}
 
-	AppSetting.ImageMaxSize = AppSetting.ImageMaxSize * 1024 * 1024
-	ServerSetting.ReadTimeout = ServerSetting.ReadTimeout * time.Second
-	ServerSetting.WriteTimeout = ServerSetting.WriteTimeout * time.Second
-	RedisSetting.IdleTimeout = RedisSetting.IdleTimeout * time.Second
+// Load 按 默认值 → ini 文件 → GINBLOG_* 环境变量 → DATABASE_URL/REDIS_URL 的顺序合并配置，
+// 后者覆盖前者，最后换算单位并校验
+func Load(path string) (*Config, error) {
+	cfg, _, err := load(resolvePath(path))
+	return cfg, err
 }
 
-func mapTo(section string, v interface{}) {
-	err := Cfg.Section(section).MapTo(v)
+func load(path string, explicit bool) (*Config, *ini.File, error) {
+	f, err := loadFile(path, explicit)
 	if err != nil {
-		log.Fatalf("Cfg.MapTo ServerSetting err: %v", err)
+		return nil, nil, err
+	}
+
+	// 兼容旧配置中的 [redis] Host
+	if sec := f.Section("redis"); sec.HasKey("Host") && !sec.HasKey("Addr") {
+		sec.Key("Addr").SetValue(sec.Key("Host").String())
+	}
+
+	cfg := defaultConfig()
+	if err := applyEnv(f, cfg); err != nil {
+		return nil, nil, err
+	}
+	for _, sec := range cfg.sections() {
+		if err := f.Section(sec.name).MapTo(sec.value); err != nil {
+			return nil, nil, fmt.Errorf("setting: map section [%s]: %v", sec.name, err)
+		}
+	}
+	cfg.convertUnits()
+
+	if err := applyURLs(cfg); err != nil {
+		return nil, nil, err
+	}
+	cfg.Database.Type = normalizeDatabaseType(cfg.Database.Type)
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, f, nil
+}
+
+// resolvePath 依次使用参数、GINBLOG_CONFIG 和 DefaultConfigPath 作为配置文件路径，explicit 表示是否为显式指定
+func resolvePath(path string) (resolved string, explicit bool) {
+	if path != "" {
+		return path, true
+	}
+	if path = os.Getenv(ENV_CONFIG); path != "" {
+		return path, true
+	}
+	return DefaultConfigPath, false
+}
+
+// loadFile 读取 ini 文件。显式指定的文件必须存在，默认路径不存在时只使用默认值和环境变量
+func loadFile(path string, explicit bool) (*ini.File, error) {
+	if _, err := os.Stat(path); !explicit && errors.Is(err, os.ErrNotExist) {
+		return ini.Empty(), nil
+	}
+
+	f, err := ini.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("setting: fail to parse '%s': %v", path, err)
+	}
+	return f, nil
+}
+
+// defaultConfig 默认配置，单位与 ini 中一致，在 convertUnits 中统一换算
+func defaultConfig() *Config {
+	return &Config{
+		App: App{
+			JwtAccessExpire:  30,
+			JwtRefreshExpire: 168,
+			PageSize:         10,
+			PrefixUrl:        "http://127.0.0.1:8000",
+			RuntimeRootPath:  "runtime/",
+			ImageSavePath:    "upload/images/",
+			ImageMaxSize:     5,
+			ImageAllowExts:   []string{".jpg", ".jpeg", ".png"},
+			LogSavePath:      "logs/",
+			LogSaveName:      "log",
+			LogFileExt:       "log",
+			TimeFormat:       "20060102",
+			LogLevel:         "info",
+			LogFormat:        "json",
+			LogMaxSize:       100,
+			LogMaxAge:        7,
+			ExportSavePath:   "export/",
+			QrCodeSavePath:   "qrcode/",
+			ViewDedupWindow:  1800,
+			SearchEngine:     "database",
+		},
+		Server: Server{
+			RunMode:         "release",
+			HttpPort:        8000,
+			ReadTimeout:     60,
+			WriteTimeout:    60,
+			ShutdownTimeout: 30,
+		},
+		Database: Database{
+			Type:        DB_MYSQL,
+			User:        "root",
+			Host:        "localhost:3306",
+			Name:        "blog",
+			TablePrefix: "blog_",
+		},
+		Redis: Redis{
+			Addr:        "localhost:6379",
+			MaxActive:   30,
+			MaxIdle:     30,
+			IdleTimeout: 200,
+		},
+		RateLimit: RateLimit{
+			Enabled:            true,
+			Auth:               "10/1m",
+			Public:             "120/1m",
+			Api:                "300/1m",
+			AuthKey:            "ip",
+			PublicKey:          "ip",
+			ApiKey:             "user",
+			LoginMaxFailures:   5,
+			LoginFailureWindow: 900,
+			LoginLockout:       900,
+		},
+	}
+}
+
+// convertUnits 把 ini 中以 MB、分钟、秒等为单位的数值换算为字节数和 time.Duration
+func (c *Config) convertUnits() {
+	c.App.ImageMaxSize = c.App.ImageMaxSize * 1024 * 1024
+	c.App.JwtAccessExpire = c.App.JwtAccessExpire * time.Minute
+	c.App.JwtRefreshExpire = c.App.JwtRefreshExpire * time.Hour
+	c.App.LogMaxSize = c.App.LogMaxSize * 1024 * 1024
+	c.App.LogMaxAge = c.App.LogMaxAge * 24 * time.Hour
+	c.App.ViewDedupWindow = c.App.ViewDedupWindow * time.Second
+	c.Server.ReadTimeout = c.Server.ReadTimeout * time.Second
+	c.Server.WriteTimeout = c.Server.WriteTimeout * time.Second
+	c.Server.ShutdownTimeout = c.Server.ShutdownTimeout * time.Second
+	c.Redis.IdleTimeout = c.Redis.IdleTimeout * time.Second
+	c.RateLimit.LoginFailureWindow = c.RateLimit.LoginFailureWindow * time.Second
+	c.RateLimit.LoginLockout = c.RateLimit.LoginLockout * time.Second
+}
+
+// normalizeDatabaseType 把数据库类型的别名转换为 GORM 使用的驱动名，无法识别时原样返回交给 Validate 报错
+func normalizeDatabaseType(t string) string {
+	switch strings.ToLower(t) {
+	case "mysql":
+		return DB_MYSQL
+	case "postgres", "postgresql", "pg":
+		return DB_POSTGRES
+	case "sqlite", "sqlite3":
+		return DB_SQLITE
+	}
+	return t
+}
+
+type section struct {
+	name  string
+	value interface{}
+}
+
+// sections ini 分组名与配置结构的对应关系
+func (c *Config) sections() []section {
+	return []section{
+		{"app", &c.App},
+		{"server", &c.Server},
+		{"database", &c.Database},
+		{"redis", &c.Redis},
+		{"ratelimit", &c.RateLimit},
 	}
 }
@@ -0,0 +1,112 @@
+package setting
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ValidationError 配置校验发现的所有问题
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "setting: invalid config:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// Validate 校验配置，一次返回所有问题而不是遇到第一个就退出
+func (c *Config) Validate() error {
+	var errs ValidationError
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	app := &c.App
+	check(app.JwtSecret != "", "[app] JwtSecret must not be empty")
+	check(app.JwtAccessExpire > 0, "[app] JwtAccessExpire must be greater than 0")
+	check(app.JwtRefreshExpire > app.JwtAccessExpire, "[app] JwtRefreshExpire must be longer than JwtAccessExpire")
+	check(app.PageSize > 0, "[app] PageSize must be greater than 0, got %d", app.PageSize)
+	check(app.ImageMaxSize > 0, "[app] ImageMaxSize must be greater than 0")
+	check(len(app.ImageAllowExts) > 0, "[app] ImageAllowExts must not be empty")
+	check(oneOf(app.LogLevel, "debug", "info", "warn", "error"), "[app] LogLevel must be one of debug, info, warn, error, got %q", app.LogLevel)
+	check(oneOf(app.LogFormat, "json", "text"), "[app] LogFormat must be json or text, got %q", app.LogFormat)
+	check(app.LogMaxSize >= 0, "[app] LogMaxSize must not be negative")
+	check(app.LogSaveName != "" && app.LogFileExt != "", "[app] LogSaveName and LogFileExt must not be empty")
+	check(app.TimeFormat != "", "[app] TimeFormat must not be empty")
+	check(oneOf(app.SearchEngine, "database", "mysql", "memory"), "[app] SearchEngine must be database or memory, got %q", app.SearchEngine)
+
+	if err := checkDir(app.RuntimeRootPath); err != nil {
+		errs = append(errs, fmt.Sprintf("[app] RuntimeRootPath %q is not usable: %v", app.RuntimeRootPath, err))
+	}
+	for key, path := range map[string]string{
+		"ImageSavePath":  app.ImageSavePath,
+		"LogSavePath":    app.LogSavePath,
+		"ExportSavePath": app.ExportSavePath,
+		"QrCodeSavePath": app.QrCodeSavePath,
+	} {
+		// 这些路径会直接拼接在 RuntimeRootPath 之后
+		check(path != "" && strings.HasSuffix(path, "/") && !strings.HasPrefix(path, "/"),
+			"[app] %s must be a relative path ending with /, got %q", key, path)
+	}
+
+	server := &c.Server
+	check(oneOf(server.RunMode, "debug", "release", "test"), "[server] RunMode must be one of debug, release, test, got %q", server.RunMode)
+	check(server.HttpPort > 0 && server.HttpPort < 65536, "[server] HttpPort must be between 1 and 65535, got %d", server.HttpPort)
+	check(server.ShutdownTimeout > 0, "[server] ShutdownTimeout must be greater than 0")
+	for _, proxy := range server.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "[server] TrustedProxies must be IPs or CIDRs, got %q", proxy)
+	}
+
+	db := &c.Database
+	check(oneOf(db.Type, DB_MYSQL, DB_POSTGRES, DB_SQLITE), "[database] Type must be one of mysql, postgres, sqlite3, got %q", db.Type)
+	// SQLite 的 Name 为数据库文件路径，不需要 Host
+	check(db.Type == DB_SQLITE || db.Host != "", "[database] Host must not be empty")
+	check(db.Name != "", "[database] Name must not be empty")
+
+	check(c.Redis.Addr != "", "[redis] Addr must not be empty")
+
+	limit := &c.RateLimit
+	for _, group := range []string{"auth", "public", "api"} {
+		rule, key := limit.Rule(group)
+		if _, err := ParseRate(rule); err != nil {
+			errs = append(errs, fmt.Sprintf("[ratelimit] %s: %v", group, err))
+		}
+		check(oneOf(key, "ip", "user", "token"), "[ratelimit] key of %s must be one of ip, user, token, got %q", group, key)
+	}
+	check(limit.LoginMaxFailures <= 0 || limit.LoginLockout > 0, "[ratelimit] LoginLockout must be greater than 0 when LoginMaxFailures is set")
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func oneOf(s string, values ...string) bool {
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDir 检查目录存在或可以创建
+func checkDir(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty path")
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(path, os.ModePerm)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory")
+	}
+	return nil
+}
@@ -48,7 +48,7 @@ func GetImageFullPath() string {
 // 返回: 如果扩展名允许返回 true，否则返回 false
 func CheckImageExt(fileName string) bool {
 	ext := file.GetFileExt(fileName) // 获取文件扩展名
-	for _, allowExt := range setting.AppSetting.ImageAllowExts {
+	for _, allowExt := range setting.Current().App.ImageAllowExts {
 		if strings.EqualFold(ext, allowExt) { // 忽略大小写比较
 			return true
 		}
@@ -65,7 +65,7 @@ func CheckImageSize(f multipart.File) bool {
 		logging.Warn(err) // 记录警告日志
 		return false
 	}
-	return size <= setting.AppSetting.ImageMaxSize // 比较文件大小和限制
+	return size <= setting.Current().App.ImageMaxSize // 比较文件大小和限制
 }
 
 // CheckImage 检查图片存储路径是否存在并验证权限
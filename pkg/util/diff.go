@@ -0,0 +1,206 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+// 行级差异的操作类型
+const (
+	DIFF_EQUAL  = "equal"
+	DIFF_INSERT = "insert"
+	DIFF_DELETE = "delete"
+)
+
+// DiffLine 差异中的一行，OldLine/NewLine 为该行在旧/新文本中的行号（从 1 开始），不存在时为 0
+type DiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// MAX_DIFF_LINES 去掉相同的首尾行后，两段文本参与比较的行数之和的上限
+const MAX_DIFF_LINES = 10000
+
+// ErrDiffTooLarge 两段文本的差异超过 MAX_DIFF_LINES
+var ErrDiffTooLarge = errors.New("util: diff too large")
+
+// DiffLines 基于 Myers 算法计算两段文本的行级差异，时间复杂度 O((N+M)D)，空间复杂度 O(N+M)，
+// D 为差异的行数
+func DiffLines(oldText, newText string) ([]DiffLine, error) {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	prefix := commonPrefix(a, b)
+	suffix := commonSuffix(a[prefix:], b[prefix:])
+	if len(a)+len(b)-2*(prefix+suffix) > MAX_DIFF_LINES {
+		return nil, ErrDiffTooLarge
+	}
+
+	d := lineDiffer{a: a, b: b, lines: make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)}
+	d.diff(0, len(a), 0, len(b))
+	return d.lines, nil
+}
+
+// lineDiffer 按顺序收集 a 到 b 的行级差异
+type lineDiffer struct {
+	a, b  []string
+	lines []DiffLine
+}
+
+// diff 计算 a[aLo:aHi] 到 b[bLo:bHi] 的差异：去掉相同的首尾行后，
+// 用 bisect 找到最短编辑路径上的一个中间点，将问题分成两半递归求解
+func (d *lineDiffer) diff(aLo, aHi, bLo, bHi int) {
+	prefix := commonPrefix(d.a[aLo:aHi], d.b[bLo:bHi])
+	for k := 0; k < prefix; k++ {
+		d.equal(aLo+k, bLo+k)
+	}
+	aLo, bLo = aLo+prefix, bLo+prefix
+
+	suffix := commonSuffix(d.a[aLo:aHi], d.b[bLo:bHi])
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		d.insert(bLo, bHi)
+	case bLo == bHi:
+		d.delete(aLo, aHi)
+	default:
+		x, y := bisect(d.a[aLo:aHi], d.b[bLo:bHi])
+		if x <= 0 && y <= 0 || x == aHi-aLo && y == bHi-bLo {
+			// 找不到可以继续拆分的中间点，整体替换
+			d.delete(aLo, aHi)
+			d.insert(bLo, bHi)
+		} else {
+			d.diff(aLo, aLo+x, bLo, bLo+y)
+			d.diff(aLo+x, aHi, bLo+y, bHi)
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		d.equal(aHi+k, bHi+k)
+	}
+}
+
+func (d *lineDiffer) equal(i, j int) {
+	d.lines = append(d.lines, DiffLine{Op: DIFF_EQUAL, OldLine: i + 1, NewLine: j + 1, Text: d.a[i]})
+}
+
+func (d *lineDiffer) delete(lo, hi int) {
+	for i := lo; i < hi; i++ {
+		d.lines = append(d.lines, DiffLine{Op: DIFF_DELETE, OldLine: i + 1, Text: d.a[i]})
+	}
+}
+
+func (d *lineDiffer) insert(lo, hi int) {
+	for j := lo; j < hi; j++ {
+		d.lines = append(d.lines, DiffLine{Op: DIFF_INSERT, NewLine: j + 1, Text: d.b[j]})
+	}
+}
+
+// bisect 从两端同时搜索 a 到 b 的最短编辑路径，返回两条路径相遇处的点 (x, y)，
+// 即最短路径经过 a[:x]、b[:y] 的分界。找不到时返回 (-1, -1)
+func bisect(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// v1[offset+k]、v2[offset+k] 分别为正向、反向搜索在对角线 k 上到达的最远 x
+	v1 := make([]int, 2*maxD+2)
+	v2 := make([]int, 2*maxD+2)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	delta := n - m
+	// delta 为奇数时正向路径会与反向路径相遇，否则反向路径会与正向路径相遇
+	front := delta%2 != 0
+	// 已越过边界的对角线不再继续搜索
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			k1Offset := offset + k1
+			var x1 int
+			if k1 == -d || k1 != d && v1[k1Offset-1] < v1[k1Offset+1] {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+
+			if x1 > n {
+				k1end += 2
+			} else if y1 > m {
+				k1start += 2
+			} else if front {
+				k2Offset := offset + delta - k1
+				if k2Offset >= 0 && k2Offset < len(v2) && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return x1, y1
+				}
+			}
+		}
+
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			k2Offset := offset + k2
+			var x2 int
+			if k2 == -d || k2 != d && v2[k2Offset-1] < v2[k2Offset+1] {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+
+			if x2 > n {
+				k2end += 2
+			} else if y2 > m {
+				k2start += 2
+			} else if !front {
+				k1Offset := offset + delta - k2
+				if k1Offset >= 0 && k1Offset < len(v1) && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					if x1 >= n-x2 {
+						return x1, x1 - (k1Offset - offset)
+					}
+				}
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// commonPrefix 返回 a、b 相同的开头行数
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonSuffix 返回 a、b 相同的结尾行数
+func commonSuffix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// applyDiff 从差异中还原旧文本和新文本，并检查行号是否连续
+func applyDiff(t *testing.T, lines []DiffLine) (string, string) {
+	t.Helper()
+
+	var oldLines, newLines []string
+	for _, line := range lines {
+		switch line.Op {
+		case DIFF_EQUAL:
+			oldLines, newLines = append(oldLines, line.Text), append(newLines, line.Text)
+		case DIFF_DELETE:
+			oldLines = append(oldLines, line.Text)
+		case DIFF_INSERT:
+			newLines = append(newLines, line.Text)
+		default:
+			t.Fatalf("unknown op %q", line.Op)
+		}
+		if line.Op != DIFF_INSERT && line.OldLine != len(oldLines) {
+			t.Errorf("%s %q old line = %d, want %d", line.Op, line.Text, line.OldLine, len(oldLines))
+		}
+		if line.Op != DIFF_DELETE && line.NewLine != len(newLines) {
+			t.Errorf("%s %q new line = %d, want %d", line.Op, line.Text, line.NewLine, len(newLines))
+		}
+	}
+	return strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		want     []DiffLine // 为空时只检查还原结果和修改的行数
+		changed  int
+	}{
+		{name: "both empty", old: "", new: ""},
+		{
+			name: "empty to text", old: "", new: "a\nb",
+			want: []DiffLine{
+				{Op: DIFF_INSERT, NewLine: 1, Text: "a"},
+				{Op: DIFF_INSERT, NewLine: 2, Text: "b"},
+			},
+		},
+		{
+			name: "text to empty", old: "a\nb", new: "",
+			want: []DiffLine{
+				{Op: DIFF_DELETE, OldLine: 1, Text: "a"},
+				{Op: DIFF_DELETE, OldLine: 2, Text: "b"},
+			},
+		},
+		{
+			name: "identical", old: "a\nb", new: "a\r\nb",
+			want: []DiffLine{
+				{Op: DIFF_EQUAL, OldLine: 1, NewLine: 1, Text: "a"},
+				{Op: DIFF_EQUAL, OldLine: 2, NewLine: 2, Text: "b"},
+			},
+		},
+		{
+			name: "pure insert", old: "a\nc", new: "a\nb\nc",
+			want: []DiffLine{
+				{Op: DIFF_EQUAL, OldLine: 1, NewLine: 1, Text: "a"},
+				{Op: DIFF_INSERT, NewLine: 2, Text: "b"},
+				{Op: DIFF_EQUAL, OldLine: 2, NewLine: 3, Text: "c"},
+			},
+		},
+		{
+			name: "pure delete", old: "a\nb\nc", new: "a\nc",
+			want: []DiffLine{
+				{Op: DIFF_EQUAL, OldLine: 1, NewLine: 1, Text: "a"},
+				{Op: DIFF_DELETE, OldLine: 2, Text: "b"},
+				{Op: DIFF_EQUAL, OldLine: 3, NewLine: 2, Text: "c"},
+			},
+		},
+		{name: "interleaved edits", old: "a\nb\nc\nd\ne\nf", new: "a\nx\nc\nd\ny\nf\ng", changed: 5},
+		{name: "moved line", old: "a\nb\nc\nd", new: "b\nc\nd\na", changed: 2},
+		{name: "no common lines", old: "a\nb\nc", new: "x\ny", changed: 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, err := DiffLines(tc.old, tc.new)
+			if err != nil {
+				t.Fatalf("DiffLines: %v", err)
+			}
+
+			oldText, newText := applyDiff(t, lines)
+			if oldText != strings.ReplaceAll(tc.old, "\r\n", "\n") || newText != strings.ReplaceAll(tc.new, "\r\n", "\n") {
+				t.Errorf("applied diff = %q -> %q, want %q -> %q", oldText, newText, tc.old, tc.new)
+			}
+
+			if tc.want != nil {
+				if !reflect.DeepEqual(lines, tc.want) {
+					t.Errorf("DiffLines = %+v, want %+v", lines, tc.want)
+				}
+				return
+			}
+
+			// Myers 算法得到的是最短编辑
+			changed := 0
+			for _, line := range lines {
+				if line.Op != DIFF_EQUAL {
+					changed++
+				}
+			}
+			if changed != tc.changed {
+				t.Errorf("changed lines = %d, want %d: %+v", changed, tc.changed, lines)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLimit(t *testing.T) {
+	numbered := func(prefix string, n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = prefix + strconv.Itoa(i)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// 参与比较的行数刚好达到上限
+	if _, err := DiffLines(numbered("a", MAX_DIFF_LINES/2), numbered("b", MAX_DIFF_LINES/2)); err != nil {
+		t.Errorf("DiffLines at the limit: %v", err)
+	}
+
+	if _, err := DiffLines(numbered("a", MAX_DIFF_LINES/2+1), numbered("b", MAX_DIFF_LINES/2)); err != ErrDiffTooLarge {
+		t.Errorf("DiffLines over the limit = %v, want ErrDiffTooLarge", err)
+	}
+
+	// 相同的首尾行不计入上限
+	common := numbered("c", MAX_DIFF_LINES)
+	lines, err := DiffLines(common+"\nold\n"+common, common+"\nnew\n"+common)
+	if err != nil {
+		t.Fatalf("DiffLines with long common prefix and suffix: %v", err)
+	}
+	if len(lines) != 2*MAX_DIFF_LINES+2 {
+		t.Errorf("DiffLines returned %d lines, want %d", len(lines), 2*MAX_DIFF_LINES+2)
+	}
+}
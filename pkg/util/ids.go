@@ -0,0 +1,36 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseIDs 解析以逗号分隔的ID列表，例如 "1,2,3"，ID 必须大于 0
+func ParseIDs(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid id: %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// RandomID 生成随机的 32 位十六进制ID，例如 JWT 的 jti
+func RandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
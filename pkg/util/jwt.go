@@ -8,19 +8,57 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+// Token 类型，refresh token 只能用于换取新的 token，不能访问接口
+const (
+	TOKEN_TYPE_ACCESS  = "access"
+	TOKEN_TYPE_REFRESH = "refresh"
+)
+
 type Claims struct {
-	Username string `json:"username"`
+	UserID    int    `json:"uid"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"typ"`
+	// IssuedAtMs 毫秒精度的签发时间，标准的 iat 只精确到秒，不足以和吊销时间比较先后
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.StandardClaims
 }
 
-func GenerateToken(username, password string) (string, error) {
+// IssuedAtMilli 返回毫秒精度的签发时间，没有 iat_ms 的旧 token 按所在秒的起点计算
+func (c *Claims) IssuedAtMilli() int64 {
+	if c.IssuedAtMs > 0 {
+		return c.IssuedAtMs
+	}
+	return c.IssuedAt * 1000
+}
+
+// GenerateToken 生成短期有效的 access token
+func GenerateToken(userID int, username, role string) (string, error) {
+	return generateToken(userID, username, role, TOKEN_TYPE_ACCESS, setting.AppSetting.JwtAccessExpire)
+}
+
+// GenerateRefreshToken 生成长期有效的 refresh token
+func GenerateRefreshToken(userID int, username, role string) (string, error) {
+	return generateToken(userID, username, role, TOKEN_TYPE_REFRESH, setting.AppSetting.JwtRefreshExpire)
+}
+
+func generateToken(userID int, username, role, tokenType string, expire time.Duration) (string, error) {
+	jti, err := RandomID()
+	if err != nil {
+		return "", err
+	}
+
 	nowTime := time.Now()
-	expireTime := nowTime.Add(3 * time.Hour)
 	claims := Claims{
+		userID,
 		username,
+		role,
+		tokenType,
+		nowTime.UnixMilli(),
 		jwt.StandardClaims{
-			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: expireTime.Unix(),
+			Id:        jti,
+			IssuedAt:  nowTime.Unix(),
+			ExpiresAt: nowTime.Add(expire).Unix(),
 			Issuer:    "gin-blog",
 		},
 	}
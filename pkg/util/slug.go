@@ -0,0 +1,24 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify 将标题转换为 URL 友好的 slug，保留字母（包括中文）和数字，其余字符替换为 "-"
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
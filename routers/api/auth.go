@@ -3,14 +3,26 @@ package api
 import (
 	"net/http"
 
+	"github.com/3Eeeecho/go-gin-example/models"
 	"github.com/3Eeeecho/go-gin-example/pkg/app"
 	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
 	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service"
 	"github.com/3Eeeecho/go-gin-example/service/auth_service"
 	"github.com/astaxie/beego/validation"
 	"github.com/gin-gonic/gin"
 )
 
+// Handler 认证接口的处理函数，用户服务由 NewHandler 注入
+type Handler struct {
+	users *auth_service.Service
+}
+
+func NewHandler(services *service.Services) *Handler {
+	return &Handler{users: services.Users}
+}
+
 type auth struct {
 	Username string `valid:"Required; MaxSize(50)"`
 	Password string `valid:"Required; MaxSize(50)"`
@@ -27,9 +39,10 @@ type auth struct {
 // @Success 200 {object} app.Response "返回成功信息，包含 Token"
 // @Failure 400 {object} app.Response "参数验证失败"
 // @Failure 401 {object} app.Response "认证失败，用户名或密码错误"
+// @Failure 429 {object} app.Response "请求过于频繁或账号已被临时锁定"
 // @Failure 500 {object} app.Response "服务器错误"
 // @Router /auth [get]
-func GetAuth(c *gin.Context) {
+func (h *Handler) GetAuth(c *gin.Context) {
 	username := c.Query("username")
 	password := c.Query("password")
 
@@ -45,7 +58,17 @@ func GetAuth(c *gin.Context) {
 		return
 	}
 
-	authService := auth_service.Auth{Username: username, Password: password}
+	authService := auth_service.Auth{Service: h.users, Username: username, Password: password}
+	// 锁定状态读取失败时不阻止登录，只记录日志
+	lockedFor, err := authService.LockedFor()
+	if err != nil {
+		logging.Warn("check login lockout failed:", err)
+	}
+	if lockedFor > 0 {
+		g.ResponseRetryAfter(http.StatusTooManyRequests, e.ERROR_AUTH_LOCKED, lockedFor)
+		return
+	}
+
 	isExist, err := authService.Check()
 	if err != nil {
 		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
@@ -53,17 +76,256 @@ func GetAuth(c *gin.Context) {
 	}
 
 	if !isExist {
+		lockedFor, err := authService.RecordFailure()
+		if err != nil {
+			logging.Warn("record login failure failed:", err)
+		}
+		if lockedFor > 0 {
+			g.ResponseRetryAfter(http.StatusTooManyRequests, e.ERROR_AUTH_LOCKED, lockedFor)
+			return
+		}
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH, nil)
+		return
+	}
+
+	if err := authService.ResetFailures(); err != nil {
+		logging.Warn("reset login failures failed:", err)
+	}
+
+	tokens, err := auth_service.NewTokenPair(authService.ID, authService.Username, authService.Role)
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, tokens)
+}
+
+type RefreshTokenForm struct {
+	RefreshToken string `form:"refresh_token" valid:"Required"`
+}
+
+// RefreshToken 刷新 Token
+// @Summary 刷新 Token
+// @Description 使用 refresh token 换取新的 access token 和 refresh token，旧的 refresh token 随即失效
+// @Tags 认证
+// @Accept  json
+// @Produce json
+// @Param refresh_token formData string true "refresh token"
+// @Success 200 {object} app.Response "返回新的 Token"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 401 {object} app.Response "refresh token 无效或已失效"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var (
+		form RefreshTokenForm
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	claims, err := util.ParseToken(form.RefreshToken)
+	if err != nil || claims.TokenType != util.TOKEN_TYPE_REFRESH {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	revoked, err := auth_service.IsTokenRevoked(claims)
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+	if revoked {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_TOKEN_REVOKED, nil)
+		return
+	}
+
+	// refresh token 只能使用一次，并发刷新时只有先占用 jti 的请求能继续
+	claimed, err := auth_service.ClaimToken(claims)
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, nil)
+		return
+	}
+	if !claimed {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_TOKEN_REVOKED, nil)
+		return
+	}
+
+	// 重新读取用户，使角色变更在刷新后生效
+	authService := auth_service.Auth{Service: h.users, Username: claims.Username}
+	user, err := authService.Get()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+	if user.ID == 0 {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH, nil)
+		return
+	}
+
+	role := user.Role
+	if role == "" {
+		role = models.ROLE_READER
+	}
+
+	tokens, err := auth_service.NewTokenPair(user.ID, user.Username, role)
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, tokens)
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 吊销当前的 access token，同时传入 refresh_token 时一并吊销
+// @Tags 认证
+// @Accept  json
+// @Produce json
+// @Param refresh_token formData string false "refresh token"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 401 {object} app.Response "Token 无效"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	g := app.Gin{C: c}
+
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	if err := auth_service.RevokeToken(claims); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_LOGOUT_FAIL, nil)
+		return
+	}
+
+	if refreshToken := c.PostForm("refresh_token"); refreshToken != "" {
+		refreshClaims, err := util.ParseToken(refreshToken)
+		if err == nil && refreshClaims.TokenType == util.TOKEN_TYPE_REFRESH && refreshClaims.UserID == claims.UserID {
+			if err := auth_service.RevokeToken(refreshClaims); err != nil {
+				g.Response(http.StatusInternalServerError, e.ERROR_AUTH_LOGOUT_FAIL, nil)
+				return
+			}
+		}
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+type RegisterForm struct {
+	Username string `form:"username" valid:"Required;MaxSize(50)"`
+	Password string `form:"password" valid:"Required;MinSize(6);MaxSize(50)"`
+}
+
+// Register 注册用户
+// @Summary 注册用户
+// @Description 创建新用户，密码使用 bcrypt 加盐哈希后保存
+// @Tags 认证
+// @Accept  json
+// @Produce json
+// @Param username formData string true "用户名"
+// @Param password formData string true "密码，6-50 位"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /auth/register [post]
+func (h *Handler) Register(c *gin.Context) {
+	var (
+		form RegisterForm
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	authService := auth_service.Auth{Service: h.users, Username: form.Username, Password: form.Password}
+	exists, err := authService.ExistByUsername()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if exists {
+		g.Response(http.StatusOK, e.ERROR_EXIST_USER, nil)
+		return
+	}
+
+	if err := authService.Register(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_ADD_USER_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+type ChangePasswordForm struct {
+	OldPassword string `form:"old_password" valid:"Required;MaxSize(50)"`
+	NewPassword string `form:"new_password" valid:"Required;MinSize(6);MaxSize(50)"`
+}
+
+// ChangePassword 修改当前用户的密码
+// @Summary 修改密码
+// @Description 校验旧密码后将当前登录用户的密码修改为新密码，此前签发的 token 全部失效并返回新的 token
+// @Tags 认证
+// @Accept  json
+// @Produce json
+// @Param old_password formData string true "旧密码"
+// @Param new_password formData string true "新密码，6-50 位"
+// @Success 200 {object} app.Response "返回新的 Token"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 401 {object} app.Response "旧密码错误"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/auth/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var (
+		form ChangePasswordForm
+		g    = app.Gin{C: c}
+	)
+
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	authService := auth_service.Auth{
+		Service:     h.users,
+		Username:    claims.Username,
+		Password:    form.OldPassword,
+		NewPassword: form.NewPassword,
+	}
+	changed, err := authService.ChangePassword()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_CHANGE_PASSWORD_FAIL, nil)
+		return
+	}
+	if !changed {
 		g.Response(http.StatusUnauthorized, e.ERROR_AUTH, nil)
 		return
 	}
 
-	token, err := util.GenerateToken(username, password)
+	// 此前签发的 token 已全部失效，返回新的 token
+	tokens, err := auth_service.NewTokenPair(claims.UserID, claims.Username, claims.Role)
 	if err != nil {
 		g.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, nil)
 		return
 	}
 
-	g.Response(http.StatusOK, e.SUCCESS, map[string]string{
-		"token": token,
-	})
+	g.Response(http.StatusOK, e.SUCCESS, tokens)
 }
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("GINBLOG_APP_JWTSECRET", "test")
+	if err := setting.SetUp(""); err != nil {
+		fmt.Fprintln(os.Stderr, "setting.SetUp:", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// setUpRouter 使用独立的内存仓库注册认证接口，没有连接数据库和 Redis。
+// /login 下的接口由中间件代替 JWT 写入用户 1（alice）的 Claims
+func setUpRouter(t *testing.T) (*gin.Engine, *models.Repositories) {
+	t.Helper()
+
+	repos := models.NewMemoryRepositories()
+	h := NewHandler(service.New(repos))
+
+	r := gin.New()
+	r.GET("/auth", h.GetAuth)
+	r.POST("/auth/register", h.Register)
+	r.POST("/auth/refresh", h.RefreshToken)
+	r.PUT("/auth/password", h.ChangePassword)
+
+	login := r.Group("/login")
+	login.Use(func(c *gin.Context) {
+		c.Set(app.CLAIMS_KEY, &util.Claims{UserID: 1, Username: "alice", Role: models.ROLE_READER})
+	})
+	login.PUT("/auth/password", h.ChangePassword)
+	return r, repos
+}
+
+// do 发送请求，form 不为空时以表单提交
+func do(t *testing.T, r *gin.Engine, method, target string, form url.Values) (int, int, json.RawMessage) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, target, w.Body.String(), err)
+	}
+	return w.Code, resp.Code, resp.Data
+}
+
+// login 以用户名和密码登录，返回状态码、错误码和签发的 token
+func login(t *testing.T, r *gin.Engine, username, password string) (int, int, *auth_service.TokenPair) {
+	t.Helper()
+
+	query := url.Values{"username": {username}, "password": {password}}
+	status, code, data := do(t, r, http.MethodGet, "/auth?"+query.Encode(), nil)
+	var tokens auth_service.TokenPair
+	if code == e.SUCCESS {
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			t.Fatalf("decode tokens: %v", err)
+		}
+	}
+	return status, code, &tokens
+}
+
+func TestRegisterAndGetAuth(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	if status, code, _ := do(t, r, http.MethodPost, "/auth/register", url.Values{"username": {"alice"}, "password": {"secret1"}}); status != http.StatusOK || code != e.SUCCESS {
+		t.Fatalf("register alice = %d, code %d, want success", status, code)
+	}
+	user, err := repos.Users.GetByUsername("alice")
+	if err != nil || user.ID == 0 || user.Role != models.ROLE_READER || !util.IsHashedPassword(user.Password) {
+		t.Fatalf("alice = %+v, %v, want reader with hashed password", user, err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		form       url.Values
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "duplicate", form: url.Values{"username": {"alice"}, "password": {"secret2"}}, wantStatus: http.StatusOK, wantCode: e.ERROR_EXIST_USER},
+		{name: "short password", form: url.Values{"username": {"bob"}, "password": {"123"}}, wantStatus: http.StatusBadRequest, wantCode: e.INVALID_PARAMS},
+	} {
+		if status, code, _ := do(t, r, http.MethodPost, "/auth/register", tc.form); status != tc.wantStatus || code != tc.wantCode {
+			t.Errorf("register %s = %d, code %d, want %d, code %d", tc.name, status, code, tc.wantStatus, tc.wantCode)
+		}
+	}
+
+	status, code, tokens := login(t, r, "alice", "secret1")
+	if status != http.StatusOK || code != e.SUCCESS {
+		t.Fatalf("login alice = %d, code %d, want success", status, code)
+	}
+	claims, err := util.ParseToken(tokens.Token)
+	if err != nil || claims.UserID != user.ID || claims.Role != models.ROLE_READER || claims.TokenType != util.TOKEN_TYPE_ACCESS {
+		t.Errorf("access token claims = %+v, %v, want reader access token for user %d", claims, err, user.ID)
+	}
+
+	// 没有 Redis 时不会锁定账号，只返回认证失败
+	if status, code, _ := login(t, r, "alice", "wrong-password"); status != http.StatusUnauthorized || code != e.ERROR_AUTH {
+		t.Errorf("login with wrong password = %d, code %d, want %d", status, code, e.ERROR_AUTH)
+	}
+
+	// access token 不能用于刷新
+	status, code, _ = do(t, r, http.MethodPost, "/auth/refresh", url.Values{"refresh_token": {tokens.Token}})
+	if status != http.StatusUnauthorized || code != e.ERROR_AUTH_CHECK_TOKEN_FAIL {
+		t.Errorf("refresh with access token = %d, code %d, want %d", status, code, e.ERROR_AUTH_CHECK_TOKEN_FAIL)
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	t.Parallel()
+
+	r, _ := setUpRouter(t)
+
+	if status, code, _ := do(t, r, http.MethodPost, "/auth/register", url.Values{"username": {"alice"}, "password": {"secret1"}}); status != http.StatusOK || code != e.SUCCESS {
+		t.Fatalf("register alice = %d, code %d, want success", status, code)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		target     string
+		form       url.Values
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "without claims", target: "/auth/password", form: url.Values{"old_password": {"secret1"}, "new_password": {"secret2"}}, wantStatus: http.StatusUnauthorized, wantCode: e.ERROR_AUTH_CHECK_TOKEN_FAIL},
+		{name: "wrong old password", target: "/login/auth/password", form: url.Values{"old_password": {"wrong"}, "new_password": {"secret2"}}, wantStatus: http.StatusUnauthorized, wantCode: e.ERROR_AUTH},
+		{name: "short new password", target: "/login/auth/password", form: url.Values{"old_password": {"secret1"}, "new_password": {"123"}}, wantStatus: http.StatusBadRequest, wantCode: e.INVALID_PARAMS},
+		// 没有 Redis 时无法吊销旧 token，密码保持不变
+		{name: "revoke unavailable", target: "/login/auth/password", form: url.Values{"old_password": {"secret1"}, "new_password": {"secret2"}}, wantStatus: http.StatusInternalServerError, wantCode: e.ERROR_CHANGE_PASSWORD_FAIL},
+	} {
+		if status, code, _ := do(t, r, http.MethodPut, tc.target, tc.form); status != tc.wantStatus || code != tc.wantCode {
+			t.Errorf("%s: PUT %s = %d, code %d, want %d, code %d", tc.name, tc.target, status, code, tc.wantStatus, tc.wantCode)
+		}
+	}
+
+	if status, code, _ := login(t, r, "alice", "secret1"); status != http.StatusOK || code != e.SUCCESS {
+		t.Errorf("login with old password = %d, code %d, want success", status, code)
+	}
+}
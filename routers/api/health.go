@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/service/health_service"
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz 存活检查
+// @Summary 存活检查
+// @Description 进程能够处理请求即返回 200，不检查外部依赖
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} app.Response "服务存活"
+// @Router /healthz [get]
+func Healthz(c *gin.Context) {
+	g := app.Gin{C: c}
+	g.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"status": health_service.STATUS_UP,
+	})
+}
+
+// Readyz 就绪检查
+// @Summary 就绪检查
+// @Description 检查 MySQL、Redis 和运行时目录，全部可用时返回 200，否则返回 503，并给出各依赖的状态和耗时
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} app.Response "服务就绪"
+// @Failure 503 {object} app.Response "服务未就绪"
+// @Router /readyz [get]
+func Readyz(c *gin.Context) {
+	g := app.Gin{C: c}
+
+	report := health_service.Ready()
+	if report.Status != health_service.STATUS_UP {
+		g.Response(http.StatusServiceUnavailable, e.ERROR_NOT_READY, report)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, report)
+}
@@ -0,0 +1,218 @@
+package public
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/markdown"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/3Eeeecho/go-gin-example/service/tag_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 公开接口的处理函数，使用的服务由 NewHandler 注入
+type Handler struct {
+	articles *article_service.Service
+	tags     *tag_service.Service
+}
+
+func NewHandler(services *service.Services) *Handler {
+	return &Handler{articles: services.Articles, tags: services.Tags}
+}
+
+// 公开接口只返回已发布的文章和启用的分类
+const (
+	articleStatePublished = 1
+	categoryStateEnabled  = 1
+)
+
+// publicCategory 公开接口返回的分类
+type publicCategory struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id"`
+}
+
+// publicArticle 公开接口返回的文章，不包含作者、定时发布等内部字段，标签和分类只保留启用的
+type publicArticle struct {
+	ID         int             `json:"id"`
+	CreatedOn  int             `json:"created_on"`
+	ModifiedOn int             `json:"modified_on"`
+	Tags       []publicTag     `json:"tags"`
+	CategoryID int             `json:"category_id"`
+	Category   *publicCategory `json:"category"`
+	Title      string
+	Slug       string `json:"slug"`
+	Desc       string `json:"desc"`
+	Content    string `json:"content"`
+	Views      int    `json:"views"`
+
+	Toc []*markdown.TocItem `json:"toc,omitempty"` // 仅 format=html 时返回
+}
+
+func newPublicArticle(article *models.Article) *publicArticle {
+	tags := make([]publicTag, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		if tag.State == tagStateEnabled {
+			tags = append(tags, newPublicTag(tag))
+		}
+	}
+
+	var (
+		categoryID int
+		category   *publicCategory
+	)
+	if article.Category.ID > 0 && article.Category.State == categoryStateEnabled {
+		categoryID = article.Category.ID
+		category = &publicCategory{
+			ID:       article.Category.ID,
+			Name:     article.Category.Name,
+			ParentID: article.Category.ParentID,
+		}
+	}
+
+	return &publicArticle{
+		ID:         article.ID,
+		CreatedOn:  article.CreatedOn,
+		ModifiedOn: article.ModifiedOn,
+		Tags:       tags,
+		CategoryID: categoryID,
+		Category:   category,
+		Title:      article.Title,
+		Slug:       article.Slug,
+		Desc:       article.Desc,
+		Content:    article.Content,
+		Views:      article.Views,
+	}
+}
+
+// GetArticle 获取已发布的文章
+// @Summary 获取已发布的文章
+// @Description 根据文章别名获取已发布的文章，无需认证
+// @Tags 公开接口
+// @Accept  json
+// @Produce json
+// @Param slug path string true "文章别名"
+// @Param format query string false "内容格式，markdown: 原文（默认），html: 渲染后的 HTML 及目录"
+// @Success 200 {object} app.Response "返回文章信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/public/articles/{slug} [get]
+func (h *Handler) GetArticle(c *gin.Context) {
+	g := app.Gin{C: c}
+	slug := c.Param("slug")
+
+	valid := validation.Validation{}
+	valid.Required(slug, "slug").Message("别名不能为空")
+	valid.MaxSize(slug, 100, "slug").Message("别名最长为100字符")
+	format := c.DefaultQuery("format", article_service.FORMAT_MARKDOWN)
+	valid.Match(format, article_service.FormatPattern, "format").Message("格式只能为 markdown 或 html")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{Service: h.articles, Slug: slug}
+	article, err := articleService.GetBySlug()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLE_FAIL, nil)
+		return
+	}
+
+	if article.ID == 0 || article.State != articleStatePublished {
+		g.Response(http.StatusNotFound, e.ERROR_NOT_EXIST_ARTICLE, nil)
+		return
+	}
+
+	articleService.ID = article.ID
+	if pending, err := articleService.AddView(app.GetVisitor(c)); err != nil {
+		logging.Warn(err)
+	} else {
+		article.Views += pending
+	}
+
+	if format == article_service.FORMAT_HTML {
+		rendered, err := article_service.Render(article)
+		if err != nil {
+			g.Response(http.StatusInternalServerError, e.ERROR_RENDER_ARTICLE_FAIL, nil)
+			return
+		}
+		result := newPublicArticle(article)
+		result.Content = rendered.Content
+		result.Toc = rendered.Toc
+		g.Response(http.StatusOK, e.SUCCESS, result)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, newPublicArticle(article))
+}
+
+// GetArticles 获取已发布的文章列表
+// @Summary 获取已发布的文章列表
+// @Description 分页获取已发布的文章，可按标签过滤，无需认证
+// @Tags 公开接口
+// @Accept  json
+// @Produce json
+// @Param tag_ids query string false "逗号分隔的多个标签ID"
+// @Param tag_match query string false "标签匹配方式，any: 包含任一标签（默认），all: 包含全部标签"
+// @Param category_id query int false "分类ID"
+// @Param sub_categories query bool false "为 true 时包含子孙分类下的文章"
+// @Param page query int false "页码"
+// @Success 200 {object} app.Response "返回文章列表和总数"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/public/articles [get]
+func (h *Handler) GetArticles(c *gin.Context) {
+	g := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	tagIDs, matchAll := app.GetTagFilter(c, &valid)
+	categoryID, subCategories := app.GetCategoryFilter(c, &valid)
+
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{
+		Service:       h.articles,
+		TagIDs:        tagIDs,
+		MatchAllTags:  matchAll,
+		CategoryID:    categoryID,
+		SubCategories: subCategories,
+		State:         articleStatePublished,
+		PageNum:       util.GetPage(c),
+		PageSize:      setting.Current().App.PageSize,
+	}
+
+	total, err := articleService.Count()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_COUNT_ARTICLE_FAIL, nil)
+		return
+	}
+
+	articles, err := articleService.GetAll()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLES_FAIL, nil)
+		return
+	}
+
+	lists := make([]*publicArticle, 0, len(articles))
+	for _, article := range articles {
+		lists = append(lists, newPublicArticle(article))
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": lists,
+		"total": total,
+	})
+}
@@ -0,0 +1,127 @@
+package public
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("GINBLOG_APP_JWTSECRET", "test")
+	if err := setting.SetUp(""); err != nil {
+		fmt.Fprintln(os.Stderr, "setting.SetUp:", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// setUpRouter 使用独立的内存仓库注册公开接口，没有连接数据库和 Redis
+func setUpRouter(t *testing.T) (*gin.Engine, *models.Repositories) {
+	t.Helper()
+
+	repos := models.NewMemoryRepositories()
+	h := NewHandler(service.New(repos))
+
+	r := gin.New()
+	r.GET("/articles/:slug", h.GetArticle)
+	r.GET("/tags", h.GetTags)
+	return r, repos
+}
+
+// get 发送 GET 请求，要求返回成功并返回原始的 data
+func get(t *testing.T, r *gin.Engine, target string) json.RawMessage {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("GET %s: decode %q: %v", target, w.Body.String(), err)
+	}
+	if w.Code != http.StatusOK || resp.Code != e.SUCCESS {
+		t.Fatalf("GET %s = %d, code %d, want success", target, w.Code, resp.Code)
+	}
+	return resp.Data
+}
+
+func TestGetTagsReturnsPublicFields(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	if err := repos.Tags.Add("Go Web", 1, "admin"); err != nil {
+		t.Fatalf("Add tag: %v", err)
+	}
+	if err := repos.Tags.Add("hidden", 0, "admin"); err != nil {
+		t.Fatalf("Add tag: %v", err)
+	}
+
+	var result struct {
+		Lists []map[string]interface{} `json:"lists"`
+		Total int                      `json:"total"`
+	}
+	if err := json.Unmarshal(get(t, r, "/tags"), &result); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if result.Total != 1 || len(result.Lists) != 1 {
+		t.Fatalf("GET /tags = %d tags, total %d, want 1", len(result.Lists), result.Total)
+	}
+	want := map[string]interface{}{"id": float64(1), "name": "Go Web", "slug": "go-web"}
+	for key, value := range want {
+		if result.Lists[0][key] != value {
+			t.Errorf("tag %s = %v, want %v", key, result.Lists[0][key], value)
+		}
+	}
+	if len(result.Lists[0]) != len(want) {
+		t.Errorf("tag fields = %v, want only id, name and slug", result.Lists[0])
+	}
+}
+
+func TestGetArticleHidesDisabledCategory(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	if err := repos.Categories.Add("enabled", 0, "", 1, "admin"); err != nil {
+		t.Fatalf("Add category: %v", err)
+	}
+	if err := repos.Categories.Add("disabled", 0, "", 0, "admin"); err != nil {
+		t.Fatalf("Add category: %v", err)
+	}
+	for i, slug := range []string{"in-enabled", "in-disabled"} {
+		_, err := repos.Articles.Add(map[string]interface{}{
+			"title": slug, "slug": slug, "category_id": i + 1, "created_by": 1, "state": 1, "tag_ids": []int{},
+		})
+		if err != nil {
+			t.Fatalf("Add article: %v", err)
+		}
+	}
+
+	for slug, wantCategory := range map[string]int{"in-enabled": 1, "in-disabled": 0} {
+		var article publicArticle
+		if err := json.Unmarshal(get(t, r, "/articles/"+slug), &article); err != nil {
+			t.Fatalf("decode article: %v", err)
+		}
+		gotCategory := 0
+		if article.Category != nil {
+			gotCategory = article.Category.ID
+		}
+		if article.CategoryID != wantCategory || gotCategory != wantCategory {
+			t.Errorf("%s category = %d (%d), want %d", slug, article.CategoryID, gotCategory, wantCategory)
+		}
+	}
+}
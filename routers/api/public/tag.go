@@ -0,0 +1,59 @@
+package public
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/tag_service"
+	"github.com/gin-gonic/gin"
+)
+
+// 公开接口只返回启用的标签
+const tagStateEnabled = 1
+
+// publicTag 公开接口返回的标签，不包含创建人、状态等内部字段
+type publicTag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"` // 标签没有单独保存 slug，由名称生成
+}
+
+func newPublicTag(tag models.Tag) publicTag {
+	return publicTag{ID: tag.ID, Name: tag.Name, Slug: util.Slugify(tag.Name)}
+}
+
+// GetTags 获取启用的标签
+// @Summary 获取启用的标签
+// @Description 获取所有启用的标签，无需认证
+// @Tags 公开接口
+// @Accept  json
+// @Produce json
+// @Success 200 {object} app.Response "返回标签列表和总数"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/public/tags [get]
+func (h *Handler) GetTags(c *gin.Context) {
+	g := app.Gin{C: c}
+
+	tagService := tag_service.Tag{Service: h.tags, State: tagStateEnabled}
+
+	tags, err := tagService.GetAll()
+	if err != nil {
+		logging.Info(err)
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_TAGS_FAIL, nil)
+		return
+	}
+
+	lists := make([]publicTag, 0, len(tags))
+	for _, tag := range tags {
+		lists = append(lists, newPublicTag(tag))
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": lists,
+		"total": len(lists),
+	})
+}
@@ -3,9 +3,13 @@ package v1
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/3Eeeecho/go-gin-example/models"
 	"github.com/3Eeeecho/go-gin-example/pkg/app"
 	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
 	"github.com/3Eeeecho/go-gin-example/pkg/qrcode"
 	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 	"github.com/3Eeeecho/go-gin-example/pkg/util"
@@ -24,16 +28,19 @@ import (
 // @Accept  json
 // @Produce json
 // @Param id path int true "文章ID"  // 必填参数，文章的ID
+// @Param format query string false "内容格式"  // 可选参数，markdown: 原文（默认），html: 渲染后的 HTML 及目录
 // @Success 200 {object} app.Response "返回文章信息"
 // @Failure 400 {object} app.Response "参数验证失败"
 // @Failure 404 {object} app.Response "文章不存在"
 // @Router /api/v1/articles/{id} [get]
-func GetArticle(c *gin.Context) {
+func (h *Handler) GetArticle(c *gin.Context) {
 	id := com.StrTo(c.Param("id")).MustInt()
 	fmt.Println("ID:", id)
 	g := app.Gin{C: c}
 	valid := validation.Validation{}
 	valid.Min(id, 1, "id").Message("ID必须大于0")
+	format := c.DefaultQuery("format", article_service.FORMAT_MARKDOWN)
+	valid.Match(format, article_service.FormatPattern, "format").Message("格式只能为 markdown 或 html")
 
 	if valid.HasErrors() {
 		app.MakrErrors(valid.Errors)
@@ -41,7 +48,7 @@ func GetArticle(c *gin.Context) {
 		return
 	}
 
-	articleService := article_service.Article{ID: id}
+	articleService := article_service.Article{Service: h.articles, ID: id}
 
 	exists, err := articleService.ExistByID()
 	if err != nil {
@@ -60,6 +67,22 @@ func GetArticle(c *gin.Context) {
 		return
 	}
 
+	if pending, err := articleService.AddView(app.GetVisitor(c)); err != nil {
+		logging.Warn(err)
+	} else {
+		article.Views += pending
+	}
+
+	if format == article_service.FORMAT_HTML {
+		rendered, err := article_service.Render(article)
+		if err != nil {
+			g.Response(http.StatusInternalServerError, e.ERROR_RENDER_ARTICLE_FAIL, nil)
+			return
+		}
+		g.Response(http.StatusOK, e.SUCCESS, rendered)
+		return
+	}
+
 	g.Response(http.StatusOK, e.SUCCESS, article)
 }
 
@@ -70,12 +93,15 @@ func GetArticle(c *gin.Context) {
 // @Accept  json
 // @Produce json
 // @Param state query int false "文章状态"  // 可选参数，0: 草稿，1: 已发布
-// @Param tag_id query int false "标签ID"  // 可选参数，标签ID，必须大于0
+// @Param tag_ids query string false "标签ID"  // 可选参数，逗号分隔的多个标签ID
+// @Param tag_match query string false "标签匹配方式"  // 可选参数，any: 包含任一标签（默认），all: 包含全部标签
+// @Param category_id query int false "分类ID"  // 可选参数，分类ID，必须大于0
+// @Param sub_categories query bool false "包含子分类"  // 可选参数，为 true 时包含子孙分类下的文章
 // @Success 200 {object} app.Response "返回文章列表和总数"
 // @Failure 400 {object} app.Response "参数验证失败"
 // @Failure 500 {object} app.Response "服务器错误"
 // @Router /api/v1/articles [get]
-func GetArticles(c *gin.Context) {
+func (h *Handler) GetArticles(c *gin.Context) {
 	g := app.Gin{C: c}
 	valid := validation.Validation{}
 	state := -1
@@ -85,11 +111,8 @@ func GetArticles(c *gin.Context) {
 		valid.Range(state, 0, 2, "state").Message("状态只允许0或1")
 	}
 
-	var tagId int = -1
-	if arg := c.Query("tag_id"); arg != "" {
-		tagId = com.StrTo(arg).MustInt()
-		valid.Min(tagId, 1, "tag_id").Message("标签ID必须大于0")
-	}
+	tagIDs, matchAll := app.GetTagFilter(c, &valid)
+	categoryID, subCategories := app.GetCategoryFilter(c, &valid)
 
 	if valid.HasErrors() {
 		app.MakrErrors(valid.Errors)
@@ -98,10 +121,14 @@ func GetArticles(c *gin.Context) {
 	}
 
 	articleService := article_service.Article{
-		TagID:    tagId,
-		State:    state,
-		PageNum:  util.GetPage(c),
-		PageSize: setting.AppSetting.PageSize,
+		Service:       h.articles,
+		TagIDs:        tagIDs,
+		MatchAllTags:  matchAll,
+		CategoryID:    categoryID,
+		SubCategories: subCategories,
+		State:         state,
+		PageNum:       util.GetPage(c),
+		PageSize:      setting.Current().App.PageSize,
 	}
 
 	total, err := articleService.Count()
@@ -123,14 +150,84 @@ func GetArticles(c *gin.Context) {
 	g.Response(http.StatusOK, e.SUCCESS, data)
 }
 
+// SearchArticles 全文搜索文章
+// @Summary 全文搜索文章
+// @Description 按标题和内容搜索文章，结果按相关度降序排列，返回带有 <em> 高亮标记的内容片段。默认只搜索已发布的文章，其他状态仅编辑及以上角色可以搜索
+// @Tags 文章
+// @Accept  json
+// @Produce json
+// @Param q query string true "搜索关键词"
+// @Param state query int false "文章状态"  // 可选参数，-1: 全部，0: 草稿，1: 已发布（默认）
+// @Param page query int false "页码"
+// @Success 200 {object} app.Response "返回搜索结果和总数"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 403 {object} app.Response "没有权限"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/search [get]
+func (h *Handler) SearchArticles(c *gin.Context) {
+	g := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	query := strings.TrimSpace(c.Query("q"))
+	valid.Required(query, "q").Message("搜索关键词不能为空")
+	valid.MaxSize(query, 100, "q").Message("搜索关键词最长为100字符")
+
+	state := 1
+	if arg := c.Query("state"); arg != "" {
+		state = com.StrTo(arg).MustInt()
+		valid.Range(state, -1, 1, "state").Message("状态只允许-1、0或1")
+	}
+
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	// 草稿只对编辑及以上角色可见
+	if state != 1 {
+		claims, ok := app.GetClaims(c)
+		if !ok {
+			g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+			return
+		}
+		if claims.Role != models.ROLE_ADMIN && claims.Role != models.ROLE_EDITOR {
+			g.Response(http.StatusForbidden, e.ERROR_AUTH_PERMISSION_DENIED, nil)
+			return
+		}
+	}
+
+	articleService := article_service.Article{
+		Service:  h.articles,
+		State:    state,
+		PageNum:  util.GetPage(c),
+		PageSize: setting.Current().App.PageSize,
+	}
+
+	hits, total, err := articleService.Search(query)
+	if err != nil {
+		logging.Warn(err)
+		g.Response(http.StatusInternalServerError, e.ERROR_SEARCH_ARTICLES_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": hits,
+		"total": total,
+	})
+}
+
 type AddArticleForm struct {
-	TagID         int    `form:"tag_id" valid:"Min(1)"`
+	TagIDs        []int  `form:"tag_ids" valid:"Required"`
+	CategoryID    int    `form:"category_id" valid:"Min(0)"`
 	Title         string `form:"title" valid:"MaxSize(100)"`
+	Slug          string `form:"slug" valid:"MaxSize(100)"`
 	Desc          string `form:"desc" valid:"MaxSize(255)"`
 	Content       string `form:"content" valid:"MaxSize(65535)"`
 	CreatedBy     int    `form:"created_by" valid:"Min(1)"`
 	CoverImageUrl string `form:"cover_image_url" valid:"MaxSize(255)"`
 	State         int    `form:"state" valid:"Range(0,1)"`
+	PublishAt     int    `form:"publish_at" valid:"Min(0)"`
 }
 
 // AddArticle 新增文章
@@ -139,18 +236,21 @@ type AddArticleForm struct {
 // @Tags 文章
 // @Accept  json
 // @Produce json
-// @Param tag_id query int true "标签ID"  // 标签ID，必填，必须大于0
+// @Param tag_ids query []int true "标签ID"  // 标签ID，必填，可重复传入多个
+// @Param category_id query int false "分类ID"  // 分类ID，可选，0 表示未分类
 // @Param title query string true "标题"  // 文章标题，必填
+// @Param slug query string false "别名"  // 文章别名，用于公开接口的访问地址，为空时根据标题生成
 // @Param desc query string true "简述"  // 文章简述，必填
 // @Param content query string true "内容"  // 文章内容，必填
 // @Param created_by query string true "创建人"  // 创建人的名称，必填
 // @Param state query int false "状态"  // 文章状态（0: 草稿, 1: 已发布）
+// @Param publish_at query int false "定时发布时间"  // Unix 时间戳，仅草稿可设置，到期后自动发布
 // @Success 200 {object} app.Response "成功返回数据"
 // @Failure 400 {object} app.Response "参数验证失败"
 // @Failure 404 {object} app.Response "标签不存在"
 // @Failure 500 {object} app.Response "服务器错误"
 // @Router /api/v1/articles [post]
-func AddArticle(c *gin.Context) {
+func (h *Handler) AddArticle(c *gin.Context) {
 	var (
 		form AddArticleForm
 		g    = app.Gin{C: c}
@@ -162,26 +262,55 @@ func AddArticle(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{ID: form.TagID}
-	exists, err := tagService.ExistByID()
-	if err != nil {
-		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_TAG_FAIL, nil)
+	// 作者只能以自己的身份发布文章
+	if claims, ok := app.GetClaims(c); ok && claims.Role == models.ROLE_AUTHOR {
+		form.CreatedBy = claims.UserID
+	}
+
+	if errCode := checkPublishAt(form.PublishAt, form.State); errCode != e.SUCCESS {
+		g.Response(http.StatusBadRequest, errCode, nil)
 		return
 	}
-	if !exists {
-		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_TAG, nil)
+
+	if httpCode, errCode := h.checkTagsExist(form.TagIDs); errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
 		return
 	}
 
+	if form.CategoryID > 0 {
+		if httpCode, errCode := h.checkCategoryExist(form.CategoryID); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
 	articleService := article_service.Article{
-		TagID:         form.TagID,
+		Service:       h.articles,
+		TagIDs:        form.TagIDs,
+		CategoryID:    form.CategoryID,
 		Title:         form.Title,
+		Slug:          util.Slugify(form.Slug),
 		Desc:          form.Desc,
 		Content:       form.Content,
 		CoverImageUrl: form.CoverImageUrl,
 		State:         form.State,
+		PublishAt:     form.PublishAt,
+		ScheduledBy:   scheduledBy(c, form.CreatedBy),
 		CreatedBy:     form.CreatedBy,
 	}
+
+	if articleService.Slug != "" {
+		exists, err := articleService.ExistBySlug()
+		if err != nil {
+			g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
+			return
+		}
+		if exists {
+			g.Response(http.StatusOK, e.ERROR_EXIST_ARTICLE_SLUG, nil)
+			return
+		}
+	}
+
 	if err := articleService.Add(); err != nil {
 		g.Response(http.StatusInternalServerError, e.ERROR_ADD_ARTICLE_FAIL, nil)
 		return
@@ -192,34 +321,38 @@ func AddArticle(c *gin.Context) {
 
 type UpdateArticleForm struct {
 	ID            int    `form:"id" valid:"Required;Min(1)"`
-	TagID         int    `form:"tag_id" valid:"Min(1)"`
+	TagIDs        []int  `form:"tag_ids"`
+	CategoryID    int    `form:"category_id" valid:"Min(0)"`
 	Title         string `form:"title" valid:"MaxSize(100)"`
+	Slug          string `form:"slug" valid:"MaxSize(100)"`
 	Desc          string `form:"desc" valid:"MaxSize(255)"`
 	Content       string `form:"content" valid:"MaxSize(65535)"`
-	ModifiedBy    int    `form:"modified_by" valid:"Min(1)"`
 	CoverImageUrl string `form:"cover_image_url" valid:"MaxSize(255)"`
 	State         int    `form:"state" valid:"Range(0,1)"`
+	PublishAt     int    `form:"publish_at" valid:"Min(-1)"`
 }
 
 // EditArticle 修改文章
 // @Summary 修改文章
-// @Description 通过文章ID和更新的参数修改文章信息（如标签ID、标题、简述、内容、修改人、状态）
+// @Description 通过文章ID和更新的参数修改文章信息（如标签ID、标题、简述、内容、状态），修改人为当前登录用户
 // @Tags 文章
 // @Accept  json
 // @Produce json
 // @Param id path int true "文章ID"  // 文章ID，必填，必须大于0
-// @Param tag_id query int false "标签ID"  // 标签ID，可选，必须大于0
+// @Param tag_ids query []int false "标签ID"  // 标签ID，可选，传入时替换文章的全部标签
+// @Param category_id query int false "分类ID"  // 分类ID，可选
 // @Param title query string false "标题"  // 文章标题，可选
+// @Param slug query string false "别名"  // 文章别名，可选
 // @Param desc query string false "简述"  // 文章简述，可选
 // @Param content query string false "内容"  // 文章内容，可选
-// @Param modified_by query string true "修改人"  // 修改人的名称，必填
 // @Param state query int false "状态"  // 文章状态（0: 草稿, 1: 已发布）
+// @Param publish_at query int false "定时发布时间"  // Unix 时间戳，仅草稿可设置，-1 表示取消定时发布
 // @Success 200 {object} app.Response "返回成功信息"
 // @Failure 400 {object} app.Response "参数验证失败"
 // @Failure 404 {object} app.Response "文章不存在"
 // @Failure 500 {object} app.Response "服务器错误"
 // @Router /api/v1/articles/{id} [put]
-func UpdateArticle(c *gin.Context) {
+func (h *Handler) UpdateArticle(c *gin.Context) {
 	var (
 		form = UpdateArticleForm{ID: com.StrTo(c.Param("id")).MustInt()}
 		g    = app.Gin{C: c}
@@ -231,15 +364,32 @@ func UpdateArticle(c *gin.Context) {
 		return
 	}
 
+	// 修改人始终是当前登录用户，不能以他人的身份修改文章
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	if errCode := checkPublishAt(form.PublishAt, form.State); errCode != e.SUCCESS {
+		g.Response(http.StatusBadRequest, errCode, nil)
+		return
+	}
+
 	articleService := article_service.Article{
+		Service:       h.articles,
 		ID:            form.ID,
-		TagID:         form.TagID,
+		TagIDs:        form.TagIDs,
+		CategoryID:    form.CategoryID,
 		Title:         form.Title,
+		Slug:          util.Slugify(form.Slug),
 		Desc:          form.Desc,
 		Content:       form.Content,
-		ModifiedBy:    form.ModifiedBy,
+		ModifiedBy:    claims.UserID,
 		CoverImageUrl: form.CoverImageUrl,
 		State:         form.State,
+		PublishAt:     form.PublishAt,
+		ScheduledBy:   claims.UserID,
 	}
 
 	exists, err := articleService.ExistByID()
@@ -252,16 +402,30 @@ func UpdateArticle(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{ID: form.TagID}
-	exists, err = tagService.ExistByID()
-	if err != nil {
-		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_TAG_FAIL, nil)
-		return
+	if len(form.TagIDs) > 0 {
+		if httpCode, errCode := h.checkTagsExist(form.TagIDs); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
 	}
 
-	if !exists {
-		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_TAG, nil)
-		return
+	if form.CategoryID > 0 {
+		if httpCode, errCode := h.checkCategoryExist(form.CategoryID); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
+	if articleService.Slug != "" {
+		exists, err = articleService.ExistBySlug()
+		if err != nil {
+			g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
+			return
+		}
+		if exists {
+			g.Response(http.StatusOK, e.ERROR_EXIST_ARTICLE_SLUG, nil)
+			return
+		}
 	}
 
 	err = articleService.Update()
@@ -286,7 +450,7 @@ func UpdateArticle(c *gin.Context) {
 // @Failure 404 {object} app.Response "文章不存在"
 // @Failure 500 {object} app.Response "服务器错误"
 // @Router /api/v1/articles/{id} [delete]
-func DeleteArticle(c *gin.Context) {
+func (h *Handler) DeleteArticle(c *gin.Context) {
 	id := com.StrTo(c.Param("id")).MustInt()
 	g := app.Gin{C: c}
 
@@ -299,7 +463,7 @@ func DeleteArticle(c *gin.Context) {
 		return
 	}
 
-	articleService := article_service.Article{ID: id}
+	articleService := article_service.Article{Service: h.articles, ID: id}
 	exists, err := articleService.ExistByID()
 	if err != nil {
 		g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
@@ -319,13 +483,52 @@ func DeleteArticle(c *gin.Context) {
 	g.Response(http.StatusOK, e.SUCCESS, nil)
 }
 
+// checkTagsExist 检查标签ID是否合法且全部存在，返回对应的 HTTP 状态码和错误码
+func (h *Handler) checkTagsExist(tagIDs []int) (int, int) {
+	for _, id := range tagIDs {
+		if id < 1 {
+			return http.StatusBadRequest, e.INVALID_PARAMS
+		}
+	}
+
+	tagService := tag_service.Tag{Service: h.tags, IDs: tagIDs}
+	exists, err := tagService.ExistByIDs()
+	if err != nil {
+		return http.StatusInternalServerError, e.ERROR_EXIST_TAG_FAIL
+	}
+	if !exists {
+		return http.StatusOK, e.ERROR_NOT_EXIST_TAG
+	}
+
+	return http.StatusOK, e.SUCCESS
+}
+
+// checkPublishAt 检查定时发布时间，只有草稿可以设置晚于当前时间的定时发布
+func checkPublishAt(publishAt int, state int) int {
+	if publishAt <= 0 {
+		return e.SUCCESS
+	}
+	if state != 0 || int64(publishAt) <= time.Now().Unix() {
+		return e.ERROR_INVALID_PUBLISH_AT
+	}
+	return e.SUCCESS
+}
+
+// scheduledBy 返回设置定时发布的用户ID，优先使用当前登录用户
+func scheduledBy(c *gin.Context, fallback int) int {
+	if claims, ok := app.GetClaims(c); ok {
+		return claims.UserID
+	}
+	return fallback
+}
+
 const (
 	QRCODE_URL = "https://github.com/3Eeeecho/gin-blog"
 )
 
-func GenerateArticlePoster(c *gin.Context) {
+func (h *Handler) GenerateArticlePoster(c *gin.Context) {
 	g := app.Gin{C: c}
-	article := &article_service.Article{}
+	article := &article_service.Article{Service: h.articles}
 	qr := qrcode.NewQrCode(QRCODE_URL, 300, 300, qr.M, qr.Auto) // 目前写死 gin 系列路径，可自行增加业务逻辑
 	posterName := article_service.GetPosterFlag() + "-" + qrcode.GetQrCodeFileName(qr.URL) + qr.GetQrCodeExt()
 	articlePoster := article_service.NewArticlePoster(posterName, article, qr)
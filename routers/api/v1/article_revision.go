@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+)
+
+// GetArticleRevisions 获取文章版本列表
+// @Summary 获取文章版本列表
+// @Description 分页获取文章的历史版本，按版本号倒序排列，不包含正文
+// @Tags 文章版本
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param page query int false "页码"
+// @Success 200 {object} app.Response "返回版本列表和总数"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/revisions [get]
+func (h *Handler) GetArticleRevisions(c *gin.Context) {
+	g := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("文章ID必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	if code := h.checkArticleExist(id); code != e.SUCCESS {
+		g.Response(http.StatusOK, code, nil)
+		return
+	}
+
+	articleService := article_service.Article{
+		Service:  h.articles,
+		ID:       id,
+		PageNum:  util.GetPage(c),
+		PageSize: setting.Current().App.PageSize,
+	}
+
+	total, err := articleService.CountRevisions()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLE_REVISIONS_FAIL, nil)
+		return
+	}
+
+	revisions, err := articleService.GetRevisions()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLE_REVISIONS_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": revisions,
+		"total": total,
+	})
+}
+
+// GetArticleRevision 获取文章的某个版本
+// @Summary 获取文章的某个版本
+// @Description 获取文章指定版本的完整快照
+// @Tags 文章版本
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} app.Response "返回版本快照"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/revisions/{revision} [get]
+func (h *Handler) GetArticleRevision(c *gin.Context) {
+	g := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	revision := com.StrTo(c.Param("revision")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("文章ID必须大于0")
+	valid.Min(revision, 1, "revision").Message("版本号必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{Service: h.articles, ID: id}
+	rev, err := articleService.GetRevision(revision)
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLE_REVISIONS_FAIL, nil)
+		return
+	}
+	if rev.ID == 0 {
+		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_ARTICLE_REVISION, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, rev)
+}
+
+// DiffArticleRevisions 比较文章的两个版本
+// @Summary 比较文章的两个版本
+// @Description 返回从版本 from 到版本 to 的标题、简述和内容的行级差异
+// @Tags 文章版本
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param from query int true "旧版本号"
+// @Param to query int true "新版本号"
+// @Success 200 {object} app.Response "返回行级差异"
+// @Failure 400 {object} app.Response "参数验证失败或差异过大"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/revisions/diff [get]
+func (h *Handler) DiffArticleRevisions(c *gin.Context) {
+	g := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	from := com.StrTo(c.Query("from")).MustInt()
+	to := com.StrTo(c.Query("to")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("文章ID必须大于0")
+	valid.Min(from, 1, "from").Message("旧版本号必须大于0")
+	valid.Min(to, 1, "to").Message("新版本号必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{Service: h.articles, ID: id}
+	for _, revision := range []int{from, to} {
+		if httpCode, errCode := checkRevisionExist(&articleService, revision); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
+	diff, err := articleService.DiffRevisions(from, to)
+	if err == util.ErrDiffTooLarge {
+		g.Response(http.StatusBadRequest, e.ERROR_ARTICLE_REVISION_DIFF_TOO_LARGE, nil)
+		return
+	}
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_ARTICLE_REVISIONS_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, diff)
+}
+
+// RestoreArticleRevision 恢复文章版本
+// @Summary 恢复文章版本
+// @Description 用指定版本的标题、简述和内容覆盖文章，恢复操作会作为一个新版本保存
+// @Tags 文章版本
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param revision path int true "要恢复的版本号"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/revisions/{revision}/restore [post]
+func (h *Handler) RestoreArticleRevision(c *gin.Context) {
+	g := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	revision := com.StrTo(c.Param("revision")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("文章ID必须大于0")
+	valid.Min(revision, 1, "revision").Message("版本号必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{Service: h.articles, ID: id}
+	if claims, ok := app.GetClaims(c); ok {
+		articleService.ModifiedBy = claims.UserID
+	}
+
+	if httpCode, errCode := checkRevisionExist(&articleService, revision); errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	if err := articleService.RestoreRevision(revision); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_RESTORE_ARTICLE_REVISION_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// checkRevisionExist 检查文章版本是否存在，返回对应的 HTTP 状态码和错误码
+func checkRevisionExist(articleService *article_service.Article, revision int) (int, int) {
+	exists, err := articleService.ExistRevision(revision)
+	if err != nil {
+		return http.StatusInternalServerError, e.ERROR_CHECK_EXIST_ARTICLE_REVISION_FAIL
+	}
+	if !exists {
+		return http.StatusOK, e.ERROR_NOT_EXIST_ARTICLE_REVISION
+	}
+	return http.StatusOK, e.SUCCESS
+}
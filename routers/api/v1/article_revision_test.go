@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+)
+
+func TestArticleRevisionHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Hello", "a\nb", "0", "1")
+	mustDo(t, r, http.MethodPut, "/articles/1", url.Values{"title": {"Hello again"}, "content": {"a\nc"}})
+
+	var list struct {
+		Lists []models.ArticleRevision `json:"lists"`
+		Total int                      `json:"total"`
+	}
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1/revisions", nil), &list); err != nil {
+		t.Fatalf("decode revisions: %v", err)
+	}
+	if list.Total != 2 || len(list.Lists) != 2 || list.Lists[0].Revision != 2 {
+		t.Fatalf("GET revisions = %d revisions, total %d, want 2 newest first", len(list.Lists), list.Total)
+	}
+
+	var revision models.ArticleRevision
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1/revisions/1", nil), &revision); err != nil {
+		t.Fatalf("decode revision: %v", err)
+	}
+	if revision.Title != "Hello" || revision.Content != "a\nb" {
+		t.Errorf("revision 1 = %q %q, want Hello a\\nb", revision.Title, revision.Content)
+	}
+
+	for _, target := range []string{"/articles/1/revisions/9", "/articles/1/revisions/diff?from=1&to=9"} {
+		status, resp := do(t, r, http.MethodGet, target, nil)
+		if resp.Code != e.ERROR_NOT_EXIST_ARTICLE_REVISION {
+			t.Errorf("GET %s = %d, code %d, want %d", target, status, resp.Code, e.ERROR_NOT_EXIST_ARTICLE_REVISION)
+		}
+	}
+	status, resp := do(t, r, http.MethodGet, "/articles/9/revisions", nil)
+	if resp.Code != e.ERROR_NOT_EXIST_ARTICLE {
+		t.Errorf("GET revisions of missing article = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_ARTICLE)
+	}
+
+	var diff article_service.RevisionDiff
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1/revisions/diff?from=1&to=2", nil), &diff); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	want := []util.DiffLine{
+		{Op: util.DIFF_EQUAL, OldLine: 1, NewLine: 1, Text: "a"},
+		{Op: util.DIFF_DELETE, OldLine: 2, Text: "b"},
+		{Op: util.DIFF_INSERT, NewLine: 2, Text: "c"},
+	}
+	if diff.From != 1 || diff.To != 2 || !reflect.DeepEqual(diff.Content, want) {
+		t.Errorf("diff 1..2 = %d..%d %+v, want %+v", diff.From, diff.To, diff.Content, want)
+	}
+
+	// 恢复操作保存为新版本，创建人取自登录用户
+	mustDo(t, r, http.MethodPost, "/articles/1/revisions/1/restore", nil)
+	article, err := repos.Articles.Get(1)
+	if err != nil || article.Title != "Hello" || article.Content != "a\nb" {
+		t.Fatalf("restored article = %q %q, %v, want Hello a\\nb", article.Title, article.Content, err)
+	}
+	restored, err := repos.Articles.GetRevision(1, 3)
+	if err != nil || restored.RestoredFrom != 1 || restored.CreatedBy != testUserID {
+		t.Errorf("revision 3 = restored from %d by %d, %v, want restored from 1 by %d", restored.RestoredFrom, restored.CreatedBy, err, testUserID)
+	}
+
+	status, resp = do(t, r, http.MethodPost, "/articles/1/revisions/9/restore", nil)
+	if resp.Code != e.ERROR_NOT_EXIST_ARTICLE_REVISION {
+		t.Errorf("restore missing revision = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_ARTICLE_REVISION)
+	}
+}
@@ -0,0 +1,264 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/3Eeeecho/go-gin-example/service/search_service"
+	"github.com/gin-gonic/gin"
+)
+
+// testUserID 测试请求的登录用户，setUpRouter 代替 JWT 中间件写入 Claims，
+// 角色取自 testRoleHeader 请求头，默认为管理员
+const (
+	testUserID     = 7
+	testRoleHeader = "X-Test-Role"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("GINBLOG_APP_JWTSECRET", "test")
+	if err := setting.SetUp(""); err != nil {
+		fmt.Fprintln(os.Stderr, "setting.SetUp:", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// setUpRouter 使用独立的内存仓库构建服务，并直接注册 v1 的处理函数，不经过鉴权中间件。
+// 没有连接数据库和 Redis，缓存全部退化为未命中
+func setUpRouter(t *testing.T) (*gin.Engine, *models.Repositories) {
+	t.Helper()
+
+	repos := models.NewMemoryRepositories()
+	h := NewHandler(service.New(repos))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		role := c.GetHeader(testRoleHeader)
+		if role == "" {
+			role = models.ROLE_ADMIN
+		}
+		c.Set(app.CLAIMS_KEY, &util.Claims{UserID: testUserID, Username: "admin", Role: role})
+	})
+	r.GET("/tags", h.GetTags)
+	r.POST("/tags", h.AddTag)
+	r.PUT("/tags/:id", h.EditTag)
+	r.DELETE("/tags/:id", h.DeleteTag)
+	r.GET("/categories", h.GetCategories)
+	r.POST("/categories", h.AddCategory)
+	r.PUT("/categories/:id/move", h.MoveCategory)
+	r.DELETE("/categories/:id", h.DeleteCategory)
+	r.GET("/articles", h.GetArticles)
+	r.GET("/articles/search", h.SearchArticles)
+	r.GET("/articles/:id", h.GetArticle)
+	r.POST("/articles", h.AddArticle)
+	r.PUT("/articles/:id", h.UpdateArticle)
+	r.GET("/articles/:id/revisions", h.GetArticleRevisions)
+	r.GET("/articles/:id/revisions/diff", h.DiffArticleRevisions)
+	r.GET("/articles/:id/revisions/:revision", h.GetArticleRevision)
+	r.POST("/articles/:id/revisions/:revision/restore", h.RestoreArticleRevision)
+	r.GET("/articles/:id/comments", h.GetComments)
+	r.POST("/articles/:id/comments", h.AddComment)
+	r.PUT("/articles/:id/comments/:comment_id", h.EditComment)
+	r.DELETE("/articles/:id/comments/:comment_id", h.DeleteComment)
+	r.GET("/comments", h.GetAuditComments)
+	r.PUT("/comments/:id/approve", h.ApproveComment)
+	r.PUT("/comments/:id/reject", h.RejectComment)
+	r.PUT("/users/:id/role", h.SetUserRole)
+	return r, repos
+}
+
+// testResponse 与 app.Response 对应，Data 留待各测试解析
+type testResponse struct {
+	Code int             `json:"code"`
+	Data json.RawMessage `json:"data"`
+}
+
+// do 以管理员身份发送请求，form 不为空时以表单提交
+func do(t *testing.T, r *gin.Engine, method, target string, form url.Values) (int, testResponse) {
+	t.Helper()
+
+	return doAs(t, r, models.ROLE_ADMIN, method, target, form)
+}
+
+// doAs 以指定角色发送请求
+func doAs(t *testing.T, r *gin.Engine, role, method, target string, form url.Values) (int, testResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set(testRoleHeader, role)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp testResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, target, w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// mustDo 发送请求并要求返回成功
+func mustDo(t *testing.T, r *gin.Engine, method, target string, form url.Values) json.RawMessage {
+	t.Helper()
+
+	status, resp := do(t, r, method, target, form)
+	if status != http.StatusOK || resp.Code != e.SUCCESS {
+		t.Fatalf("%s %s = %d, code %d, want success", method, target, status, resp.Code)
+	}
+	return resp.Data
+}
+
+func addArticle(t *testing.T, r *gin.Engine, title, content string, categoryID string, tagIDs ...string) {
+	t.Helper()
+
+	mustDo(t, r, http.MethodPost, "/articles", url.Values{
+		"title":       {title},
+		"desc":        {title},
+		"content":     {content},
+		"created_by":  {"1"},
+		"state":       {"1"},
+		"category_id": {categoryID},
+		"tag_ids":     tagIDs,
+	})
+}
+
+func TestArticleHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"web"}, "created_by": {"admin"}, "state": {"1"}})
+	mustDo(t, r, http.MethodPost, "/categories", url.Values{"name": {"backend"}, "created_by": {"admin"}, "state": {"1"}})
+
+	addArticle(t, r, "Hello Gin", "routing", "1", "1", "2")
+	addArticle(t, r, "Other", "mentions gin", "0", "2")
+
+	// 写入的数据只存在于内存仓库中
+	total, err := repos.Articles.Count(nil, nil)
+	if err != nil || total != 2 {
+		t.Fatalf("memory article count = %d, %v, want 2", total, err)
+	}
+
+	var article models.Article
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1", nil), &article); err != nil {
+		t.Fatalf("decode article: %v", err)
+	}
+	if article.Title != "Hello Gin" || article.Slug != "hello-gin" || len(article.Tags) != 2 || article.Category.Name != "backend" {
+		t.Errorf("GET /articles/1 = %q (slug %q) with %d tags in category %q, want Hello Gin (hello-gin) with 2 tags in backend",
+			article.Title, article.Slug, len(article.Tags), article.Category.Name)
+	}
+
+	status, resp := do(t, r, http.MethodGet, "/articles/9", nil)
+	if status != http.StatusInternalServerError || resp.Code != e.ERROR_NOT_EXIST_ARTICLE {
+		t.Errorf("GET /articles/9 = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_ARTICLE)
+	}
+
+	status, resp = do(t, r, http.MethodPost, "/articles", url.Values{
+		"title": {"Bad"}, "created_by": {"1"}, "tag_ids": {"3"},
+	})
+	if resp.Code != e.ERROR_NOT_EXIST_TAG {
+		t.Errorf("POST /articles with unknown tag = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_TAG)
+	}
+
+	var list struct {
+		Lists []models.Article `json:"lists"`
+		Total int              `json:"total"`
+	}
+	for target, want := range map[string]int{
+		"/articles":                           2,
+		"/articles?tag_ids=1":                 1,
+		"/articles?tag_ids=1,2&tag_match=all": 1,
+		"/articles?category_id=1":             1,
+	} {
+		if err := json.Unmarshal(mustDo(t, r, http.MethodGet, target, nil), &list); err != nil {
+			t.Fatalf("decode %s: %v", target, err)
+		}
+		if list.Total != want || len(list.Lists) != want {
+			t.Errorf("GET %s = %d articles, total %d, want %d", target, len(list.Lists), list.Total, want)
+		}
+	}
+}
+
+func TestUpdateArticleUsesLoginUser(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Hello", "first", "0", "1")
+
+	// 表单中的 modified_by 被忽略
+	mustDo(t, r, http.MethodPut, "/articles/1", url.Values{"title": {"Hello again"}, "modified_by": {"99"}})
+
+	article, err := repos.Articles.Get(1)
+	if err != nil || article.Title != "Hello again" || article.ModifiedBy != testUserID {
+		t.Fatalf("article = %q modified by %d, %v, want Hello again modified by %d", article.Title, article.ModifiedBy, err, testUserID)
+	}
+	revision, err := repos.Articles.GetRevision(1, 2)
+	if err != nil || revision.CreatedBy != testUserID {
+		t.Errorf("revision 2 created by %d, %v, want %d", revision.CreatedBy, err, testUserID)
+	}
+}
+
+func TestSearchArticlesHandler(t *testing.T) {
+	t.Parallel()
+
+	r, _ := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Intro", "this post mentions gin once", "0", "1")
+	addArticle(t, r, "Gin routing", "routes", "0", "1")
+	addArticle(t, r, "Unrelated", "nothing here", "0", "1")
+	mustDo(t, r, http.MethodPost, "/articles", url.Values{
+		"title": {"Gin draft"}, "created_by": {"1"}, "state": {"0"}, "tag_ids": {"1"},
+	})
+
+	var result struct {
+		Lists []search_service.Hit `json:"lists"`
+		Total int                  `json:"total"`
+	}
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/search?q=gin", nil), &result); err != nil {
+		t.Fatalf("decode search result: %v", err)
+	}
+	if result.Total != 2 || len(result.Lists) != 2 {
+		t.Fatalf("search gin = %d hits, total %d, want 2", len(result.Lists), result.Total)
+	}
+	// 标题命中的文章排在前面
+	if result.Lists[0].Title != "Gin routing" {
+		t.Errorf("first hit = %q, want Gin routing", result.Lists[0].Title)
+	}
+
+	// 编辑及以上角色才能搜索草稿
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/search?q=gin&state=-1", nil), &result); err != nil {
+		t.Fatalf("decode search result: %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("search gin in all states = total %d, want 3", result.Total)
+	}
+	status, resp := doAs(t, r, models.ROLE_AUTHOR, http.MethodGet, "/articles/search?q=gin&state=0", nil)
+	if status != http.StatusForbidden || resp.Code != e.ERROR_AUTH_PERMISSION_DENIED {
+		t.Errorf("author searching drafts = %d, code %d, want %d", status, resp.Code, e.ERROR_AUTH_PERMISSION_DENIED)
+	}
+
+	status, resp = do(t, r, http.MethodGet, "/articles/search?q=", nil)
+	if status != http.StatusBadRequest || resp.Code != e.INVALID_PARAMS {
+		t.Errorf("search without q = %d, code %d, want %d", status, resp.Code, e.INVALID_PARAMS)
+	}
+}
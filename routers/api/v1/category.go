@@ -0,0 +1,321 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/service/category_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+)
+
+// GetCategories 获取分类树
+// @Summary 获取分类树
+// @Description 获取全部分类并按父子关系组装成树
+// @Tags 分类
+// @Accept  json
+// @Produce json
+// @Param state query int false "分类状态"  // 可选参数，0: 禁用，1: 启用
+// @Success 200 {object} app.Response "返回分类树"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/categories [get]
+func (h *Handler) GetCategories(c *gin.Context) {
+	g := app.Gin{C: c}
+	state := -1
+	if arg := c.Query("state"); arg != "" {
+		state = com.StrTo(arg).MustInt()
+	}
+
+	categoryService := category_service.Category{Service: h.categories, State: state}
+	categories, err := categoryService.GetTree()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_CATEGORIES_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": categories,
+	})
+}
+
+type AddCategoryForm struct {
+	Name      string `form:"name" valid:"Required;MaxSize(100)"`
+	ParentID  int    `form:"parent_id" valid:"Min(0)"`
+	CreatedBy string `form:"created_by" valid:"Required;MaxSize(100)"`
+	State     int    `form:"state" valid:"Range(0,1)"`
+}
+
+// AddCategory 新增分类
+// @Summary 新增分类
+// @Description 在指定父分类下新增分类，parent_id 为 0 时新增顶级分类
+// @Tags 分类
+// @Accept  json
+// @Produce json
+// @Param name query string true "分类名称"
+// @Param parent_id query int false "父分类ID"
+// @Param created_by query string true "创建人"
+// @Param state query int false "分类状态"  // 可选参数，0: 禁用，1: 启用
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/categories [post]
+func (h *Handler) AddCategory(c *gin.Context) {
+	var (
+		form AddCategoryForm
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	if form.ParentID > 0 {
+		if httpCode, errCode := h.checkCategoryExist(form.ParentID); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
+	categoryService := category_service.Category{
+		Service:   h.categories,
+		Name:      form.Name,
+		ParentID:  form.ParentID,
+		CreatedBy: form.CreatedBy,
+		State:     form.State,
+	}
+
+	exists, err := categoryService.ExistByName()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL, nil)
+		return
+	}
+	if exists {
+		g.Response(http.StatusOK, e.ERROR_EXIST_CATEGORY, nil)
+		return
+	}
+
+	if err := categoryService.Add(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_ADD_CATEGORY_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+type EditCategoryForm struct {
+	ID         int    `form:"id" valid:"Required;Min(1)"`
+	Name       string `form:"name" valid:"Required;MaxSize(100)"`
+	ModifiedBy string `form:"modified_by" valid:"Required;MaxSize(100)"`
+	State      int    `form:"state" valid:"Range(0,1)"`
+}
+
+// EditCategory 修改分类
+// @Summary 修改分类
+// @Description 修改分类名称和状态，调整层级请使用移动分类接口
+// @Tags 分类
+// @Accept  json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param name query string true "分类名称"
+// @Param modified_by query string true "修改人"
+// @Param state query int false "分类状态"  // 可选参数，0: 禁用，1: 启用
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/categories/{id} [put]
+func (h *Handler) EditCategory(c *gin.Context) {
+	var (
+		form = EditCategoryForm{ID: com.StrTo(c.Param("id")).MustInt()}
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	category, err := (&category_service.Category{Service: h.categories, ID: form.ID}).Get()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL, nil)
+		return
+	}
+	if category.ID == 0 {
+		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_CATEGORY, nil)
+		return
+	}
+
+	categoryService := category_service.Category{
+		Service:    h.categories,
+		ID:         form.ID,
+		Name:       form.Name,
+		ParentID:   category.ParentID,
+		ModifiedBy: form.ModifiedBy,
+		State:      form.State,
+	}
+
+	exists, err := categoryService.ExistByName()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL, nil)
+		return
+	}
+	if exists {
+		g.Response(http.StatusOK, e.ERROR_EXIST_CATEGORY, nil)
+		return
+	}
+
+	if err := categoryService.Edit(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EDIT_CATEGORY_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+type MoveCategoryForm struct {
+	ID         int    `form:"id" valid:"Required;Min(1)"`
+	ParentID   int    `form:"parent_id" valid:"Min(0)"`
+	ModifiedBy string `form:"modified_by" valid:"Required;MaxSize(100)"`
+}
+
+// MoveCategory 移动分类
+// @Summary 移动分类
+// @Description 将分类连同其子分类移动到新的父分类下，不能移动到自身或其子分类下
+// @Tags 分类
+// @Accept  json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param parent_id query int true "新的父分类ID，0 表示移动为顶级分类"
+// @Param modified_by query string true "修改人"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/categories/{id}/move [put]
+func (h *Handler) MoveCategory(c *gin.Context) {
+	var (
+		form = MoveCategoryForm{ID: com.StrTo(c.Param("id")).MustInt()}
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	category, err := (&category_service.Category{Service: h.categories, ID: form.ID}).Get()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL, nil)
+		return
+	}
+	if category.ID == 0 {
+		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_CATEGORY, nil)
+		return
+	}
+
+	if form.ParentID > 0 {
+		if httpCode, errCode := h.checkCategoryExist(form.ParentID); errCode != e.SUCCESS {
+			g.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
+	categoryService := category_service.Category{
+		Service:    h.categories,
+		ID:         form.ID,
+		Name:       category.Name,
+		ParentID:   form.ParentID,
+		ModifiedBy: form.ModifiedBy,
+	}
+
+	ok, err := categoryService.CanMoveTo()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_MOVE_CATEGORY_FAIL, nil)
+		return
+	}
+	if !ok {
+		g.Response(http.StatusOK, e.ERROR_MOVE_CATEGORY_CYCLE, nil)
+		return
+	}
+
+	exists, err := categoryService.ExistByName()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL, nil)
+		return
+	}
+	if exists {
+		g.Response(http.StatusOK, e.ERROR_EXIST_CATEGORY, nil)
+		return
+	}
+
+	if err := categoryService.Move(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_MOVE_CATEGORY_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// DeleteCategory 删除分类
+// @Summary 删除分类
+// @Description 删除没有子分类的分类，属于该分类的文章会变为未分类
+// @Tags 分类
+// @Accept  json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/categories/{id} [delete]
+func (h *Handler) DeleteCategory(c *gin.Context) {
+	g := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	if httpCode, errCode := h.checkCategoryExist(id); errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	categoryService := category_service.Category{Service: h.categories, ID: id}
+	hasChildren, err := categoryService.HasChildren()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_DELETE_CATEGORY_FAIL, nil)
+		return
+	}
+	if hasChildren {
+		g.Response(http.StatusOK, e.ERROR_CATEGORY_HAS_CHILDREN, nil)
+		return
+	}
+
+	if err := categoryService.Delete(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_DELETE_CATEGORY_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// checkCategoryExist 检查分类是否存在，返回对应的 HTTP 状态码和错误码
+func (h *Handler) checkCategoryExist(id int) (int, int) {
+	categoryService := category_service.Category{Service: h.categories, ID: id}
+	exists, err := categoryService.ExistByID()
+	if err != nil {
+		return http.StatusInternalServerError, e.ERROR_EXIST_CATEGORY_FAIL
+	}
+	if !exists {
+		return http.StatusOK, e.ERROR_NOT_EXIST_CATEGORY
+	}
+	return http.StatusOK, e.SUCCESS
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/gin-gonic/gin"
+)
+
+func addCategory(t *testing.T, r *gin.Engine, name, parentID string) {
+	t.Helper()
+
+	mustDo(t, r, http.MethodPost, "/categories", url.Values{
+		"name": {name}, "parent_id": {parentID}, "created_by": {"admin"}, "state": {"1"},
+	})
+}
+
+func TestCategoryHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	addCategory(t, r, "backend", "0")   // 1
+	addCategory(t, r, "go", "1")        // 2
+	addCategory(t, r, "frontend", "0")  // 3
+	addCategory(t, r, "framework", "2") // 4
+
+	status, resp := do(t, r, http.MethodPost, "/categories", url.Values{
+		"name": {"go"}, "parent_id": {"1"}, "created_by": {"admin"},
+	})
+	if resp.Code != e.ERROR_EXIST_CATEGORY {
+		t.Errorf("POST duplicate category = %d, code %d, want %d", status, resp.Code, e.ERROR_EXIST_CATEGORY)
+	}
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Gin", "gin", "4", "1")
+	addArticle(t, r, "Vue", "vue", "3", "1")
+
+	var tree struct {
+		Lists []*models.Category `json:"lists"`
+	}
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/categories", nil), &tree); err != nil {
+		t.Fatalf("decode categories: %v", err)
+	}
+	if len(tree.Lists) != 2 || len(tree.Lists[0].Children) != 1 || len(tree.Lists[0].Children[0].Children) != 1 {
+		t.Fatalf("GET /categories returned an unexpected tree: %+v", tree.Lists)
+	}
+
+	countArticles := func(target string) int {
+		t.Helper()
+
+		var list struct {
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal(mustDo(t, r, http.MethodGet, target, nil), &list); err != nil {
+			t.Fatalf("decode %s: %v", target, err)
+		}
+		return list.Total
+	}
+	if n := countArticles("/articles?category_id=1&sub_categories=true"); n != 1 {
+		t.Errorf("articles under backend = %d, want 1", n)
+	}
+
+	// 不能移动到自身的子分类下
+	status, resp = do(t, r, http.MethodPut, "/categories/1/move", url.Values{"parent_id": {"4"}, "modified_by": {"admin"}})
+	if resp.Code != e.ERROR_MOVE_CATEGORY_CYCLE {
+		t.Errorf("move into descendant = %d, code %d, want %d", status, resp.Code, e.ERROR_MOVE_CATEGORY_CYCLE)
+	}
+
+	mustDo(t, r, http.MethodPut, "/categories/2/move", url.Values{"parent_id": {"3"}, "modified_by": {"admin"}})
+	framework, err := repos.Categories.Get(4)
+	if err != nil || framework.Path != "/3/2/4/" {
+		t.Fatalf("path after move = %q, %v, want /3/2/4/", framework.Path, err)
+	}
+	if n := countArticles("/articles?category_id=1&sub_categories=true"); n != 0 {
+		t.Errorf("articles under backend after move = %d, want 0", n)
+	}
+	if n := countArticles("/articles?category_id=3&sub_categories=true"); n != 2 {
+		t.Errorf("articles under frontend after move = %d, want 2", n)
+	}
+
+	status, resp = do(t, r, http.MethodDelete, "/categories/2", nil)
+	if resp.Code != e.ERROR_CATEGORY_HAS_CHILDREN {
+		t.Errorf("delete category with children = %d, code %d, want %d", status, resp.Code, e.ERROR_CATEGORY_HAS_CHILDREN)
+	}
+
+	// 删除分类后文章变为未分类
+	mustDo(t, r, http.MethodDelete, "/categories/4", nil)
+	article, err := repos.Articles.Get(1)
+	if err != nil || article.CategoryID != 0 {
+		t.Errorf("article category after delete = %d, %v, want 0", article.CategoryID, err)
+	}
+
+	// 父分类被禁用时，启用的子分类挂到最近的启用祖先下
+	mustDo(t, r, http.MethodPost, "/categories", url.Values{
+		"name": {"drafts"}, "parent_id": {"3"}, "created_by": {"admin"}, "state": {"0"},
+	}) // 5
+	addCategory(t, r, "notes", "5") // 6
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/categories?state=1", nil), &tree); err != nil {
+		t.Fatalf("decode enabled categories: %v", err)
+	}
+	var frontend *models.Category
+	for _, root := range tree.Lists {
+		if root.ID == 3 {
+			frontend = root
+		}
+	}
+	if frontend == nil || len(frontend.Children) != 2 || frontend.Children[1].Name != "notes" {
+		t.Errorf("GET /categories?state=1 returned an unexpected tree: %+v", tree.Lists)
+	}
+}
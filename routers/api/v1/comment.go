@@ -0,0 +1,421 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/3Eeeecho/go-gin-example/service/comment_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+)
+
+// GetComments 获取文章评论
+// @Summary 获取文章评论
+// @Description 获取文章下已通过审核的评论，按回复关系组装成树
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Success 200 {object} app.Response "返回评论树和评论总数"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/comments [get]
+func (h *Handler) GetComments(c *gin.Context) {
+	g := app.Gin{C: c}
+	articleID := com.StrTo(c.Param("id")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(articleID, 1, "id").Message("文章ID必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	if code := h.checkArticleExist(articleID); code != e.SUCCESS {
+		g.Response(http.StatusOK, code, nil)
+		return
+	}
+
+	commentService := comment_service.Comment{Service: h.comments, ArticleID: articleID}
+	comments, err := commentService.GetTree()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_COMMENTS_FAIL, nil)
+		return
+	}
+
+	total, err := commentService.CountApproved()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_COUNT_COMMENT_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": comments,
+		"total": total,
+	})
+}
+
+type AddCommentForm struct {
+	ArticleID int    `form:"article_id" valid:"Required;Min(1)"`
+	ParentID  int    `form:"parent_id" valid:"Min(0)"`
+	Content   string `form:"content" valid:"Required;MaxSize(1000)"`
+}
+
+// AddComment 新增评论
+// @Summary 新增评论或回复
+// @Description 只能评论已发布的文章，只能回复已通过审核的评论。评论人为当前登录用户，新增的评论处于待审核状态，审核通过后才会展示
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param parent_id query int false "回复的评论ID，0 表示顶层评论"
+// @Param content query string true "评论内容"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/comments [post]
+func (h *Handler) AddComment(c *gin.Context) {
+	var (
+		form = AddCommentForm{ArticleID: com.StrTo(c.Param("id")).MustInt()}
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	if code := h.checkArticlePublished(form.ArticleID); code != e.SUCCESS {
+		g.Response(http.StatusOK, code, nil)
+		return
+	}
+
+	if form.ParentID > 0 {
+		parentService := comment_service.Comment{Service: h.comments, ID: form.ParentID}
+		parent, err := parentService.Get()
+		if err != nil {
+			g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_COMMENT_FAIL, nil)
+			return
+		}
+		if parent.ID == 0 || parent.ArticleID != form.ArticleID {
+			g.Response(http.StatusOK, e.ERROR_NOT_EXIST_PARENT_COMMENT, nil)
+			return
+		}
+		if parent.State != models.COMMENT_STATE_APPROVED {
+			g.Response(http.StatusOK, e.ERROR_PARENT_COMMENT_NOT_APPROVED, nil)
+			return
+		}
+	}
+
+	commentService := comment_service.Comment{
+		Service:   h.comments,
+		ArticleID: form.ArticleID,
+		ParentID:  form.ParentID,
+		Content:   form.Content,
+		CreatedBy: claims.Username,
+	}
+	if err := commentService.Add(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_ADD_COMMENT_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+type EditCommentForm struct {
+	ID        int    `form:"id" valid:"Required;Min(1)"`
+	ArticleID int    `form:"article_id" valid:"Required;Min(1)"`
+	Content   string `form:"content" valid:"Required;MaxSize(1000)"`
+}
+
+// EditComment 修改评论
+// @Summary 修改评论
+// @Description 修改评论内容，修改人为当前登录用户，修改后的评论需要重新审核
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param comment_id path int true "评论ID"
+// @Param content query string true "评论内容"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/comments/{comment_id} [put]
+func (h *Handler) EditComment(c *gin.Context) {
+	var (
+		form = EditCommentForm{
+			ID:        com.StrTo(c.Param("comment_id")).MustInt(),
+			ArticleID: com.StrTo(c.Param("id")).MustInt(),
+		}
+		g = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	if code := h.checkCommentExist(form.ID, form.ArticleID); code != e.SUCCESS {
+		g.Response(http.StatusOK, code, nil)
+		return
+	}
+
+	commentService := comment_service.Comment{
+		Service:    h.comments,
+		ID:         form.ID,
+		ArticleID:  form.ArticleID,
+		Content:    form.Content,
+		ModifiedBy: claims.Username,
+	}
+	if err := commentService.Edit(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_EDIT_COMMENT_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// DeleteComment 删除评论
+// @Summary 删除评论
+// @Description 删除评论以及它下面的所有回复
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param comment_id path int true "评论ID"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/articles/{id}/comments/{comment_id} [delete]
+func (h *Handler) DeleteComment(c *gin.Context) {
+	g := app.Gin{C: c}
+	articleID := com.StrTo(c.Param("id")).MustInt()
+	id := com.StrTo(c.Param("comment_id")).MustInt()
+
+	valid := validation.Validation{}
+	valid.Min(articleID, 1, "id").Message("文章ID必须大于0")
+	valid.Min(id, 1, "comment_id").Message("评论ID必须大于0")
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	if code := h.checkCommentExist(id, articleID); code != e.SUCCESS {
+		g.Response(http.StatusOK, code, nil)
+		return
+	}
+
+	commentService := comment_service.Comment{Service: h.comments, ID: id, ArticleID: articleID}
+	if err := commentService.Delete(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_DELETE_COMMENT_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// GetAuditComments 获取评论审核队列
+// @Summary 获取评论审核队列
+// @Description 按状态分页获取评论，默认返回待审核的评论
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param state query int false "评论状态，0: 待审核，1: 已通过，2: 已拒绝"
+// @Param article_id query int false "文章ID"
+// @Success 200 {object} app.Response "返回评论列表和总数"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/comments [get]
+func (h *Handler) GetAuditComments(c *gin.Context) {
+	g := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	state := models.COMMENT_STATE_PENDING
+	if arg := c.Query("state"); arg != "" {
+		state = com.StrTo(arg).MustInt()
+		valid.Range(state, 0, 2, "state").Message("状态只允许0、1或2")
+	}
+
+	articleID := 0
+	if arg := c.Query("article_id"); arg != "" {
+		articleID = com.StrTo(arg).MustInt()
+		valid.Min(articleID, 1, "article_id").Message("文章ID必须大于0")
+	}
+
+	if valid.HasErrors() {
+		app.MakrErrors(valid.Errors)
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	commentService := comment_service.Comment{
+		Service:   h.comments,
+		ArticleID: articleID,
+		State:     state,
+		PageNum:   util.GetPage(c),
+		PageSize:  setting.Current().App.PageSize,
+	}
+
+	total, err := commentService.Count()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_COUNT_COMMENT_FAIL, nil)
+		return
+	}
+
+	comments, err := commentService.GetAll()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_GET_COMMENTS_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, map[string]interface{}{
+		"lists": comments,
+		"total": total,
+	})
+}
+
+type AuditCommentForm struct {
+	ID int `form:"id" valid:"Required;Min(1)"`
+}
+
+// ApproveComment 审核通过评论
+// @Summary 审核通过评论
+// @Description 仅管理员可以审核，审核人为当前登录用户
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "评论ID"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/comments/{id}/approve [put]
+func (h *Handler) ApproveComment(c *gin.Context) {
+	h.auditComment(c, models.COMMENT_STATE_APPROVED)
+}
+
+// RejectComment 审核拒绝评论
+// @Summary 审核拒绝评论
+// @Description 仅管理员可以审核，审核人为当前登录用户
+// @Tags 评论
+// @Accept  json
+// @Produce json
+// @Param id path int true "评论ID"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/comments/{id}/reject [put]
+func (h *Handler) RejectComment(c *gin.Context) {
+	h.auditComment(c, models.COMMENT_STATE_REJECTED)
+}
+
+func (h *Handler) auditComment(c *gin.Context, state int) {
+	var (
+		form = AuditCommentForm{ID: com.StrTo(c.Param("id")).MustInt()}
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	claims, ok := app.GetClaims(c)
+	if !ok {
+		g.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	comment, err := (&comment_service.Comment{Service: h.comments, ID: form.ID}).Get()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_COMMENT_FAIL, nil)
+		return
+	}
+	if comment.ID == 0 {
+		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_COMMENT, nil)
+		return
+	}
+
+	commentService := comment_service.Comment{
+		Service:    h.comments,
+		ID:         comment.ID,
+		ArticleID:  comment.ArticleID,
+		State:      state,
+		ModifiedBy: claims.Username,
+	}
+	if err := commentService.Audit(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_AUDIT_COMMENT_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
+
+// checkArticleExist 检查文章是否存在，返回对应的错误码
+func (h *Handler) checkArticleExist(id int) int {
+	articleService := article_service.Article{Service: h.articles, ID: id}
+	exists, err := articleService.ExistByID()
+	if err != nil {
+		return e.ERROR_CHECK_EXIST_ARTICLE_FAIL
+	}
+	if !exists {
+		return e.ERROR_NOT_EXIST_ARTICLE
+	}
+	return e.SUCCESS
+}
+
+// checkArticlePublished 检查文章是否存在且已发布，草稿和尚未到定时发布时间的文章不能评论
+func (h *Handler) checkArticlePublished(id int) int {
+	articleService := article_service.Article{Service: h.articles, ID: id}
+	exists, err := articleService.ExistByID()
+	if err != nil {
+		return e.ERROR_CHECK_EXIST_ARTICLE_FAIL
+	}
+	if !exists {
+		return e.ERROR_NOT_EXIST_ARTICLE
+	}
+
+	article, err := articleService.Get()
+	if err != nil {
+		return e.ERROR_GET_ARTICLE_FAIL
+	}
+	if article.State != 1 {
+		return e.ERROR_COMMENT_ARTICLE_NOT_PUBLISHED
+	}
+	return e.SUCCESS
+}
+
+// checkCommentExist 检查评论是否存在且属于指定文章，返回对应的错误码
+func (h *Handler) checkCommentExist(id, articleID int) int {
+	commentService := comment_service.Comment{Service: h.comments, ID: id}
+	comment, err := commentService.Get()
+	if err != nil {
+		return e.ERROR_CHECK_EXIST_COMMENT_FAIL
+	}
+	if comment.ID == 0 || comment.ArticleID != articleID {
+		return e.ERROR_NOT_EXIST_COMMENT
+	}
+	return e.SUCCESS
+}
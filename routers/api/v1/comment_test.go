@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+)
+
+func TestCommentHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Published", "content", "0", "1")
+	mustDo(t, r, http.MethodPost, "/articles", url.Values{
+		"title": {"Draft"}, "created_by": {"1"}, "state": {"0"}, "tag_ids": {"1"},
+	})
+
+	status, resp := do(t, r, http.MethodPost, "/articles/2/comments", url.Values{"content": {"hi"}})
+	if resp.Code != e.ERROR_COMMENT_ARTICLE_NOT_PUBLISHED {
+		t.Errorf("comment on draft = %d, code %d, want %d", status, resp.Code, e.ERROR_COMMENT_ARTICLE_NOT_PUBLISHED)
+	}
+
+	// 表单中的 created_by 被忽略
+	mustDo(t, r, http.MethodPost, "/articles/1/comments", url.Values{"content": {"first"}, "created_by": {"someone"}})
+	comment, err := repos.Comments.Get(1)
+	if err != nil || comment.CreatedBy != "admin" || comment.State != models.COMMENT_STATE_PENDING {
+		t.Fatalf("comment = %+v, %v, want pending comment created by admin", comment, err)
+	}
+
+	status, resp = do(t, r, http.MethodPost, "/articles/1/comments", url.Values{"content": {"reply"}, "parent_id": {"1"}})
+	if resp.Code != e.ERROR_PARENT_COMMENT_NOT_APPROVED {
+		t.Errorf("reply to pending comment = %d, code %d, want %d", status, resp.Code, e.ERROR_PARENT_COMMENT_NOT_APPROVED)
+	}
+
+	mustDo(t, r, http.MethodPut, "/comments/1/approve", url.Values{"modified_by": {"someone"}})
+	comment, err = repos.Comments.Get(1)
+	if err != nil || comment.ModifiedBy != "admin" || comment.State != models.COMMENT_STATE_APPROVED {
+		t.Fatalf("comment = %+v, %v, want approved by admin", comment, err)
+	}
+
+	mustDo(t, r, http.MethodPost, "/articles/1/comments", url.Values{"content": {"reply"}, "parent_id": {"1"}})
+	mustDo(t, r, http.MethodPut, "/comments/2/approve", nil)
+
+	var tree struct {
+		Lists []*models.Comment `json:"lists"`
+		Total int               `json:"total"`
+	}
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1/comments", nil), &tree); err != nil {
+		t.Fatalf("decode comments: %v", err)
+	}
+	if tree.Total != 2 || len(tree.Lists) != 1 || len(tree.Lists[0].Children) != 1 {
+		t.Errorf("GET comments = %d roots, total %d, want 1 root with 1 reply and total 2", len(tree.Lists), tree.Total)
+	}
+}
+
+func TestCommentModerationHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "First", "content", "0", "1")
+	addArticle(t, r, "Second", "content", "0", "1")
+	mustDo(t, r, http.MethodPost, "/articles/1/comments", url.Values{"content": {"first"}})
+	mustDo(t, r, http.MethodPost, "/articles/1/comments", url.Values{"content": {"spam"}})
+	mustDo(t, r, http.MethodPut, "/comments/1/approve", nil)
+
+	var queue struct {
+		Lists []*models.Comment `json:"lists"`
+		Total int               `json:"total"`
+	}
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/comments", nil), &queue); err != nil {
+		t.Fatalf("decode audit queue: %v", err)
+	}
+	if queue.Total != 1 || len(queue.Lists) != 1 || queue.Lists[0].ID != 2 {
+		t.Fatalf("audit queue = %d comments, total %d, want only comment 2", len(queue.Lists), queue.Total)
+	}
+
+	mustDo(t, r, http.MethodPut, "/comments/2/reject", nil)
+	comment, err := repos.Comments.Get(2)
+	if err != nil || comment.State != models.COMMENT_STATE_REJECTED || comment.ModifiedBy != "admin" {
+		t.Errorf("comment 2 = %+v, %v, want rejected by admin", comment, err)
+	}
+
+	// 修改后的评论需要重新审核
+	mustDo(t, r, http.MethodPut, "/articles/1/comments/1", url.Values{"content": {"edited"}})
+	comment, err = repos.Comments.Get(1)
+	if err != nil || comment.Content != "edited" || comment.State != models.COMMENT_STATE_PENDING {
+		t.Errorf("comment 1 = %+v, %v, want pending with edited content", comment, err)
+	}
+
+	// 评论必须属于路径中的文章
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		status, resp := do(t, r, method, "/articles/2/comments/1", url.Values{"content": {"moved"}})
+		if resp.Code != e.ERROR_NOT_EXIST_COMMENT {
+			t.Errorf("%s comment of another article = %d, code %d, want %d", method, status, resp.Code, e.ERROR_NOT_EXIST_COMMENT)
+		}
+	}
+
+	mustDo(t, r, http.MethodDelete, "/articles/1/comments/1", nil)
+	if exists, err := repos.Comments.ExistByID(1); err != nil || exists {
+		t.Errorf("comment 1 exists = %v, %v, want deleted", exists, err)
+	}
+}
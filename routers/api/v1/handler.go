@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"github.com/3Eeeecho/go-gin-example/service"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
+	"github.com/3Eeeecho/go-gin-example/service/category_service"
+	"github.com/3Eeeecho/go-gin-example/service/comment_service"
+	"github.com/3Eeeecho/go-gin-example/service/tag_service"
+)
+
+// Handler v1 接口的处理函数，使用的服务由 NewHandler 注入
+type Handler struct {
+	articles   *article_service.Service
+	tags       *tag_service.Service
+	categories *category_service.Service
+	comments   *comment_service.Service
+	users      *auth_service.Service
+}
+
+func NewHandler(services *service.Services) *Handler {
+	return &Handler{
+		articles:   services.Articles,
+		tags:       services.Tags,
+		categories: services.Categories,
+		comments:   services.Comments,
+		users:      services.Users,
+	}
+}
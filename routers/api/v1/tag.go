@@ -25,7 +25,7 @@ import (
 // @Param state query int false "标签状态"  // 可选参数，按状态过滤，0: 禁用，1: 启用
 // @Success 200 {object} app.Response "返回标签列表和总数"
 // @Router /api/v1/tags [get]
-func GetTags(c *gin.Context) {
+func (h *Handler) GetTags(c *gin.Context) {
 	g := app.Gin{C: c}
 	name := c.Query("name")
 	state := -1
@@ -34,10 +34,11 @@ func GetTags(c *gin.Context) {
 	}
 
 	tagService := tag_service.Tag{
+		Service:  h.tags,
 		Name:     name,
 		State:    state,
 		PageNum:  util.GetPage(c),
-		PageSize: setting.AppSetting.PageSize,
+		PageSize: setting.Current().App.PageSize,
 	}
 
 	tags, err := tagService.GetAll()
@@ -78,7 +79,7 @@ type AddTagForm struct {
 // @Success 200 {object} app.Response "返回成功信息"
 // @Failure 400 {object} app.Response "标签不存在"
 // @Router /api/v1/tags [post]
-func AddTag(c *gin.Context) {
+func (h *Handler) AddTag(c *gin.Context) {
 	var (
 		form AddTagForm
 		g    = app.Gin{C: c}
@@ -92,6 +93,7 @@ func AddTag(c *gin.Context) {
 	}
 
 	tagService := tag_service.Tag{
+		Service:   h.tags,
 		Name:      form.Name,
 		CreatedBy: form.CreatedBy,
 		State:     form.State,
@@ -137,7 +139,7 @@ type EditTagForm struct {
 // @Success 200 {object} app.Response "返回成功信息"
 // @Failure 400 {object} app.Response "标签不存在"
 // @Router /api/v1/tags/{id} [put]
-func EditTag(c *gin.Context) {
+func (h *Handler) EditTag(c *gin.Context) {
 	var (
 		form = EditTagForm{ID: com.StrTo(c.Param("id")).MustInt()}
 		g    = app.Gin{C: c}
@@ -150,6 +152,7 @@ func EditTag(c *gin.Context) {
 	}
 
 	tagService := tag_service.Tag{
+		Service:    h.tags,
 		ID:         form.ID,
 		Name:       form.Name,
 		ModifiedBy: form.ModifiedBy,
@@ -186,7 +189,7 @@ func EditTag(c *gin.Context) {
 // @Success 200 {object} app.Response "返回成功信息"
 // @Failure 400 {object} app.Response "标签不存在"
 // @Router /api/v1/tags/{id} [delete]
-func DeleteTag(c *gin.Context) {
+func (h *Handler) DeleteTag(c *gin.Context) {
 	id := com.StrTo(c.Param("id")).MustInt()
 
 	g := app.Gin{C: c}
@@ -199,7 +202,7 @@ func DeleteTag(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{ID: id}
+	tagService := tag_service.Tag{Service: h.tags, ID: id}
 	exist, err := tagService.ExistByID()
 	if err != nil {
 		g.Response(http.StatusInternalServerError, e.ERROR_EXIST_TAG_FAIL, nil)
@@ -230,8 +233,8 @@ func DeleteTag(c *gin.Context) {
 // @Param state formData int false "标签状态（可选），1=启用，0=禁用"
 // @Success 200 {object} map[string]string "导出成功"
 // @Failure 500 {object} app.Response "导出失败"
-// @Router /api/tags/export [post]
-func ExportTag(c *gin.Context) {
+// @Router /api/v1/tags/export [post]
+func (h *Handler) ExportTag(c *gin.Context) {
 	g := app.Gin{C: c}
 	name := c.PostForm("name")
 
@@ -243,8 +246,9 @@ func ExportTag(c *gin.Context) {
 	}
 
 	tagService := tag_service.Tag{
-		Name:  name,
-		State: state,
+		Service: h.tags,
+		Name:    name,
+		State:   state,
 	}
 
 	filename, err := tagService.Export()
@@ -269,8 +273,8 @@ func ExportTag(c *gin.Context) {
 // @Param file formData file true "文件"
 // @Success 200 {object} map[string]string "导入成功"
 // @Failure 500 {object} app.Response "导入失败"
-// @Router /api/tags/import [post]
-func ImportTag(c *gin.Context) {
+// @Router /api/v1/tags/import [post]
+func (h *Handler) ImportTag(c *gin.Context) {
 	g := app.Gin{C: c}
 
 	file, _, err := c.Request.FormFile("file")
@@ -280,7 +284,7 @@ func ImportTag(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{}
+	tagService := tag_service.Tag{Service: h.tags}
 	err = tagService.Import(file)
 	if err != nil {
 		logging.Warn(err)
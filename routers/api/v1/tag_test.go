@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+)
+
+func TestTagHandlers(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	mustDo(t, r, http.MethodPost, "/tags", url.Values{"name": {"web"}, "created_by": {"admin"}, "state": {"1"}})
+	addArticle(t, r, "Hello", "content", "0", "1", "2")
+
+	status, resp := do(t, r, http.MethodPost, "/tags", url.Values{"name": {"go"}, "created_by": {"admin"}, "state": {"1"}})
+	if resp.Code != e.ERROR_EXIST_TAG {
+		t.Errorf("POST duplicate tag = %d, code %d, want %d", status, resp.Code, e.ERROR_EXIST_TAG)
+	}
+
+	mustDo(t, r, http.MethodPut, "/tags/1", url.Values{"name": {"golang"}, "modified_by": {"editor"}, "state": {"0"}})
+	tags, err := repos.Tags.List(0, 0, map[string]interface{}{"id": 1})
+	if err != nil || len(tags) != 1 || tags[0].Name != "golang" || tags[0].ModifiedBy != "editor" || tags[0].State != 0 {
+		t.Fatalf("tag 1 = %+v, %v, want disabled golang modified by editor", tags, err)
+	}
+
+	status, resp = do(t, r, http.MethodPut, "/tags/9", url.Values{"name": {"x"}, "modified_by": {"editor"}})
+	if resp.Code != e.ERROR_NOT_EXIST_TAG {
+		t.Errorf("PUT /tags/9 = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_TAG)
+	}
+	status, resp = do(t, r, http.MethodPut, "/tags/1", url.Values{"name": {"golang"}})
+	if status != http.StatusBadRequest || resp.Code != e.INVALID_PARAMS {
+		t.Errorf("PUT /tags/1 without modified_by = %d, code %d, want %d", status, resp.Code, e.INVALID_PARAMS)
+	}
+
+	// 删除标签时同时删除它与文章的关联
+	mustDo(t, r, http.MethodDelete, "/tags/2", nil)
+	if exists, err := repos.Tags.ExistByID(2); err != nil || exists {
+		t.Errorf("tag 2 exists = %v, %v, want deleted", exists, err)
+	}
+	var article models.Article
+	if err := json.Unmarshal(mustDo(t, r, http.MethodGet, "/articles/1", nil), &article); err != nil {
+		t.Fatalf("decode article: %v", err)
+	}
+	if len(article.Tags) != 1 || article.Tags[0].ID != 1 {
+		t.Errorf("article tags = %+v, want only tag 1", article.Tags)
+	}
+
+	status, resp = do(t, r, http.MethodDelete, "/tags/2", nil)
+	if resp.Code != e.ERROR_NOT_EXIST_TAG {
+		t.Errorf("DELETE deleted tag = %d, code %d, want %d", status, resp.Code, e.ERROR_NOT_EXIST_TAG)
+	}
+}
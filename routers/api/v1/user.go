@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/app"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+)
+
+type SetUserRoleForm struct {
+	ID   int    `form:"id" valid:"Required;Min(1)"`
+	Role string `form:"role" valid:"Required"`
+}
+
+// SetUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 仅管理员可调用，修改后该用户此前签发的 token 全部失效，需要重新登录才能获得新角色的权限
+// @Tags 用户
+// @Accept  json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param role formData string true "角色：admin、editor、author、reader"
+// @Success 200 {object} app.Response "返回成功信息"
+// @Failure 400 {object} app.Response "参数验证失败"
+// @Failure 403 {object} app.Response "没有权限"
+// @Failure 500 {object} app.Response "服务器错误"
+// @Router /api/v1/users/{id}/role [put]
+func (h *Handler) SetUserRole(c *gin.Context) {
+	var (
+		form = SetUserRoleForm{ID: com.StrTo(c.Param("id")).MustInt()}
+		g    = app.Gin{C: c}
+	)
+
+	httpCode, errCode := app.BindAndValue(c, &form)
+	if errCode != e.SUCCESS {
+		g.Response(httpCode, errCode, nil)
+		return
+	}
+
+	if !models.IsValidRole(form.Role) {
+		g.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		return
+	}
+
+	authService := auth_service.Auth{Service: h.users, ID: form.ID, Role: form.Role}
+	exists, err := authService.ExistByID()
+	if err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if !exists {
+		g.Response(http.StatusOK, e.ERROR_NOT_EXIST_USER, nil)
+		return
+	}
+
+	if err := authService.SetRole(); err != nil {
+		g.Response(http.StatusInternalServerError, e.ERROR_SET_USER_ROLE_FAIL, nil)
+		return
+	}
+
+	g.Response(http.StatusOK, e.SUCCESS, nil)
+}
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+)
+
+func TestSetUserRoleHandler(t *testing.T) {
+	t.Parallel()
+
+	r, repos := setUpRouter(t)
+
+	if err := repos.Users.Add("alice", "secret", models.ROLE_READER); err != nil {
+		t.Fatalf("Add user: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		target     string
+		role       string
+		wantStatus int
+		wantCode   int
+	}{
+		{name: "invalid role", target: "/users/1/role", role: "root", wantStatus: http.StatusBadRequest, wantCode: e.INVALID_PARAMS},
+		{name: "missing user", target: "/users/9/role", role: models.ROLE_EDITOR, wantStatus: http.StatusOK, wantCode: e.ERROR_NOT_EXIST_USER},
+		// 没有 Redis 时无法吊销旧 token，角色保持不变
+		{name: "revoke unavailable", target: "/users/1/role", role: models.ROLE_EDITOR, wantStatus: http.StatusInternalServerError, wantCode: e.ERROR_SET_USER_ROLE_FAIL},
+	} {
+		status, resp := do(t, r, http.MethodPut, tc.target, url.Values{"role": {tc.role}})
+		if status != tc.wantStatus || resp.Code != tc.wantCode {
+			t.Errorf("%s: PUT %s = %d, code %d, want %d, code %d", tc.name, tc.target, status, resp.Code, tc.wantStatus, tc.wantCode)
+		}
+	}
+
+	user, err := repos.Users.GetByUsername("alice")
+	if err != nil || user.Role != models.ROLE_READER {
+		t.Errorf("alice role = %q, %v, want %q", user.Role, err, models.ROLE_READER)
+	}
+}
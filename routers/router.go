@@ -2,66 +2,160 @@ package routers
 
 import (
 	_ "github.com/3Eeeecho/go-gin-example/docs"
+	"github.com/3Eeeecho/go-gin-example/middleware/accesslog"
 	"github.com/3Eeeecho/go-gin-example/middleware/jwt"
+	"github.com/3Eeeecho/go-gin-example/middleware/metrics"
+	"github.com/3Eeeecho/go-gin-example/middleware/ratelimit"
+	"github.com/3Eeeecho/go-gin-example/middleware/rbac"
+	"github.com/3Eeeecho/go-gin-example/middleware/requestid"
+	"github.com/3Eeeecho/go-gin-example/models"
 	"github.com/3Eeeecho/go-gin-example/pkg/export"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
 	"github.com/3Eeeecho/go-gin-example/pkg/qrcode"
 	"github.com/3Eeeecho/go-gin-example/pkg/setting"
 	"github.com/3Eeeecho/go-gin-example/pkg/upload"
 	"github.com/3Eeeecho/go-gin-example/routers/api"
+	"github.com/3Eeeecho/go-gin-example/routers/api/public"
 	v1 "github.com/3Eeeecho/go-gin-example/routers/api/v1"
+	"github.com/3Eeeecho/go-gin-example/service"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files" // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 // InitRouter 初始化Gin路由
-// @Description 配置并返回一个Gin路由实例，注册API路由、Swagger文档和中间件
+// @Description 配置并返回一个Gin路由实例，注册API路由、Swagger文档和中间件。
+// services 通过 service.New 构建，会注入到各组接口的处理函数中。
+// 基于 models.NewMemoryRepositories() 构建时业务接口不访问数据库，只有就绪检查会报告数据库不可用
 // @Return *gin.Engine Gin引擎实例
-func InitRouter() *gin.Engine {
+func InitRouter(services *service.Services) *gin.Engine {
+	var (
+		authHandler   = api.NewHandler(services)
+		publicHandler = public.NewHandler(services)
+		v1Handler     = v1.NewHandler(services)
+	)
+
 	r := gin.New()
+	if err := r.SetTrustedProxies(setting.ServerSetting.TrustedProxies); err != nil {
+		logging.Error(err)
+	}
 
-	r.Use(gin.Logger())
+	r.Use(requestid.RequestID())
+	r.Use(accesslog.AccessLog())
 	r.Use(gin.Recovery())
+	r.Use(metrics.Metrics())
 	gin.SetMode(setting.ServerSetting.RunMode)
 
+	//健康检查
+	r.GET("/healthz", api.Healthz)
+	r.GET("/readyz", api.Readyz)
+	//监控指标
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	r.Static("/upload/images", upload.GetImageFullPath())
 	r.Static("/export", export.GetExcelPath())
 	r.Static("/qrcode", qrcode.GetQrCodeFullPath())
 
-	r.GET("/auth", api.GetAuth)
+	authLimit := ratelimit.RateLimit("auth")
+	r.GET("/auth", authLimit, authHandler.GetAuth)
+	r.POST("/auth/register", authLimit, authHandler.Register)
+	r.POST("/auth/refresh", authLimit, authHandler.RefreshToken)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.POST("/upload", api.UpLoadImage)
 
+	var (
+		admin  = rbac.Require(models.ROLE_ADMIN)
+		editor = rbac.Require(models.ROLE_ADMIN, models.ROLE_EDITOR)
+		author = rbac.Require(models.ROLE_ADMIN, models.ROLE_EDITOR, models.ROLE_AUTHOR)
+		owner  = rbac.ArticleOwner(services.Articles, models.ROLE_ADMIN, models.ROLE_EDITOR)
+	)
+
+	apiPublic := r.Group("/api/public")
+	apiPublic.Use(ratelimit.RateLimit("public"))
+	{
+		//获取已发布的文章列表
+		apiPublic.GET("/articles", publicHandler.GetArticles)
+		//根据别名获取已发布的文章
+		apiPublic.GET("/articles/:slug", publicHandler.GetArticle)
+		//获取启用的标签
+		apiPublic.GET("/tags", publicHandler.GetTags)
+	}
+
 	apiv1 := r.Group("/api/v1")
 	apiv1.Use(jwt.JWT())
+	apiv1.Use(ratelimit.RateLimit("api"))
 	{
+		//修改密码
+		apiv1.PUT("/auth/password", authHandler.ChangePassword)
+		//退出登录
+		apiv1.POST("/auth/logout", authHandler.Logout)
+		//修改用户角色
+		apiv1.PUT("/users/:id/role", admin, v1Handler.SetUserRole)
+
 		//获取标签列表
-		apiv1.GET("/tags", v1.GetTags)
+		apiv1.GET("/tags", v1Handler.GetTags)
 		//新建标签
-		apiv1.POST("/tags", v1.AddTag)
+		apiv1.POST("/tags", editor, v1Handler.AddTag)
 		//更新指定标签
-		apiv1.PUT("/tags/:id", v1.EditTag)
+		apiv1.PUT("/tags/:id", editor, v1Handler.EditTag)
 		//删除指定标签
-		apiv1.DELETE("/tags/:id", v1.DeleteTag)
+		apiv1.DELETE("/tags/:id", admin, v1Handler.DeleteTag)
+
+		//获取分类树
+		apiv1.GET("/categories", v1Handler.GetCategories)
+		//新建分类
+		apiv1.POST("/categories", editor, v1Handler.AddCategory)
+		//更新指定分类
+		apiv1.PUT("/categories/:id", editor, v1Handler.EditCategory)
+		//移动指定分类
+		apiv1.PUT("/categories/:id/move", editor, v1Handler.MoveCategory)
+		//删除指定分类
+		apiv1.DELETE("/categories/:id", admin, v1Handler.DeleteCategory)
 
 		//获取文章列表
-		apiv1.GET("/articles", v1.GetArticles)
+		apiv1.GET("/articles", v1Handler.GetArticles)
+		//搜索文章
+		apiv1.GET("/articles/search", v1Handler.SearchArticles)
 		//获取指定文章
-		apiv1.GET("/articles/:id", v1.GetArticle)
+		apiv1.GET("/articles/:id", v1Handler.GetArticle)
 		//新建文章
-		apiv1.POST("/articles", v1.AddArticle)
+		apiv1.POST("/articles", author, v1Handler.AddArticle)
 		//更新指定文章
-		apiv1.PUT("/articles/:id", v1.UpdateArticle)
+		apiv1.PUT("/articles/:id", author, owner, v1Handler.UpdateArticle)
 		//删除指定文章
-		apiv1.DELETE("/articles/:id", v1.DeleteArticle)
+		apiv1.DELETE("/articles/:id", author, owner, v1Handler.DeleteArticle)
 		//生成文章海报
-		apiv1.POST("/articles/poster/generate", v1.GenerateArticlePoster)
+		apiv1.POST("/articles/poster/generate", author, v1Handler.GenerateArticlePoster)
 
-		//导出标签
-		r.POST("/tags/export", v1.ExportTag)
+		//获取文章版本列表
+		apiv1.GET("/articles/:id/revisions", author, owner, v1Handler.GetArticleRevisions)
+		//比较文章的两个版本
+		apiv1.GET("/articles/:id/revisions/diff", author, owner, v1Handler.DiffArticleRevisions)
+		//获取文章的指定版本
+		apiv1.GET("/articles/:id/revisions/:revision", author, owner, v1Handler.GetArticleRevision)
+		//恢复文章的指定版本
+		apiv1.POST("/articles/:id/revisions/:revision/restore", author, owner, v1Handler.RestoreArticleRevision)
+
+		//获取文章评论
+		apiv1.GET("/articles/:id/comments", v1Handler.GetComments)
+		//新建评论
+		apiv1.POST("/articles/:id/comments", v1Handler.AddComment)
+		//更新指定评论
+		apiv1.PUT("/articles/:id/comments/:comment_id", editor, v1Handler.EditComment)
+		//删除指定评论
+		apiv1.DELETE("/articles/:id/comments/:comment_id", editor, v1Handler.DeleteComment)
+		//获取评论审核队列
+		apiv1.GET("/comments", admin, v1Handler.GetAuditComments)
+		//审核通过评论
+		apiv1.PUT("/comments/:id/approve", admin, v1Handler.ApproveComment)
+		//审核拒绝评论
+		apiv1.PUT("/comments/:id/reject", admin, v1Handler.RejectComment)
 
+		//导出标签
+		apiv1.POST("/tags/export", editor, v1Handler.ExportTag)
 		//导入标签
-		r.POST("/tags/import", v1.ImportTag)
+		apiv1.POST("/tags/import", editor, v1Handler.ImportTag)
 	}
 
 	return r
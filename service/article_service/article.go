@@ -2,22 +2,44 @@ package article_service
 
 import (
 	"context"
-	"encoding/json"
+	"strconv"
 
 	"github.com/3Eeeecho/go-gin-example/models"
 	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
 	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
 	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+	"github.com/3Eeeecho/go-gin-example/service/search_service"
 )
 
+// Service 文章服务依赖的仓库和搜索服务，由 NewService 注入
+type Service struct {
+	repo         models.ArticleRepository
+	categoryRepo models.CategoryRepository // 按分类过滤时读取子孙分类
+	search       *search_service.Service
+}
+
+func NewService(articles models.ArticleRepository, categories models.CategoryRepository, search *search_service.Service) *Service {
+	return &Service{repo: articles, categoryRepo: categories, search: search}
+}
+
+// Article 一次文章操作的参数，Service 必须设置
 type Article struct {
+	*Service
+
 	ID            int
-	TagID         int
+	TagIDs        []int
+	MatchAllTags  bool // 按标签过滤时是否要求包含全部标签
+	CategoryID    int
+	SubCategories bool // 按分类过滤时是否包含子孙分类下的文章
 	Title         string
+	Slug          string
 	Desc          string
 	Content       string
 	CoverImageUrl string
 	State         int
+	PublishAt     int // 定时发布时间，-1 表示取消定时发布
+	ScheduledBy   int // 设置定时发布的用户ID
 	CreatedBy     int
 	ModifiedBy    int
 
@@ -26,31 +48,49 @@ type Article struct {
 }
 
 func (a *Article) Add() error {
+	slug, err := a.generateSlug()
+	if err != nil {
+		return err
+	}
+
 	article := map[string]interface{}{
-		"tag_id":          a.TagID,
+		"tag_ids":         a.TagIDs,
+		"category_id":     a.CategoryID,
 		"title":           a.Title,
+		"slug":            slug,
 		"desc":            a.Desc,
 		"content":         a.Content,
 		"created_by":      a.CreatedBy,
 		"cover_image_url": a.CoverImageUrl,
 		"state":           a.State,
+		"publish_at":      a.PublishAt,
+		"scheduled_by":    a.ScheduledBy,
 	}
 
-	if err := models.AddArticle(article); err != nil {
+	id, err := a.repo.Add(article)
+	if err != nil {
 		return err
 	}
+
+	a.ID = id
+	clearCache(a.ID)
+	clearSlugCache(slug)
+	a.search.Index(a.ID)
 	return nil
 }
 
 func (a *Article) Update() error {
 	updateData := make(map[string]interface{})
 
-	if a.TagID != 0 {
-		updateData["tag_id"] = a.TagID
-	}
 	if a.Title != "" {
 		updateData["title"] = a.Title
 	}
+	if a.Slug != "" {
+		updateData["slug"] = a.Slug
+	}
+	if a.CategoryID != 0 {
+		updateData["category_id"] = a.CategoryID
+	}
 	if a.Desc != "" {
 		updateData["desc"] = a.Desc
 	}
@@ -66,90 +106,133 @@ func (a *Article) Update() error {
 	if a.ModifiedBy != 0 {
 		updateData["modified_by"] = a.ModifiedBy
 	}
+	switch {
+	case a.PublishAt > 0:
+		updateData["publish_at"] = a.PublishAt
+		updateData["scheduled_by"] = a.ScheduledBy
+	case a.PublishAt < 0 || a.State == 1:
+		// 取消定时发布，手动发布的文章也不再需要定时发布
+		updateData["publish_at"] = 0
+		updateData["scheduled_by"] = 0
+	}
 
-	if len(updateData) == 0 {
-		return nil // 没有需要更新的字段
+	if len(a.TagIDs) > 0 {
+		updateData["tag_ids"] = a.TagIDs
 	}
 
-	return models.UpdateArticle(a.ID, updateData)
+	if len(updateData) > 0 {
+		if err := a.repo.Update(a.ID, updateData); err != nil {
+			return err
+		}
+	}
+
+	clearCache(a.ID)
+	if a.Slug != "" {
+		clearSlugCache(a.Slug)
+	}
+	a.search.Index(a.ID)
+	return nil
 }
 
+// Get 获取文章，文章不存在时返回 ID 为 0 的空文章
 func (a *Article) Get() (*models.Article, error) {
-	var cacheArticle *models.Article
-
 	cache := cache_service.Article{ID: a.ID}
-	key := cache.GetArticleKey()
 	ctx := context.Background()
 
-	exists, err := gredis.Exists(ctx, key)
+	article, err := gredis.Fetch(ctx, cache.GetArticleKey(), gredis.DefaultFetchOptions.Named("article").Versioned(cache.GetArticleVersionKey()), func() (*models.Article, error) {
+		article, err := a.repo.Get(a.ID)
+		if err != nil {
+			return nil, err
+		}
+		if article.ID == 0 {
+			return nil, gredis.ErrNotFound
+		}
+		return article, nil
+	})
+	if err == gredis.ErrNotFound {
+		return &models.Article{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	//redis存在缓存数据，直接读取返回
-	if exists {
-		data, err := gredis.Get(ctx, key)
+	return article, nil
+}
+
+// GetBySlug 根据 slug 获取文章，slug 到ID的映射和文章本身都会被缓存
+func (a *Article) GetBySlug() (*models.Article, error) {
+	ctx := context.Background()
+	cache := cache_service.Article{Slug: a.Slug}
+	key := cache.GetArticleSlugKey()
+
+	id, err := gredis.Fetch(ctx, key, gredis.DefaultFetchOptions.Named("article_slug"), func() (int, error) {
+		id, err := a.repo.GetIDBySlug(a.Slug)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-
-		if err := json.Unmarshal(data, &cacheArticle); err != nil {
-			return nil, err
+		if id == 0 {
+			return 0, gredis.ErrNotFound
 		}
-
-		return cacheArticle, nil
+		return id, nil
+	})
+	if err == gredis.ErrNotFound {
+		return &models.Article{}, nil
 	}
-
-	//不然从mysql中读取并存入缓存
-	article, err := models.GetArticle(a.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := gredis.Set(ctx, key, article, 3600); err != nil {
+	article, err := (&Article{Service: a.Service, ID: id}).Get()
+	if err != nil {
 		return nil, err
 	}
 
+	// 文章修改了 slug 或已被删除时，缓存中的映射已经过时，删除后重新查询
+	if article.ID == 0 || article.Slug != a.Slug {
+		if err := gredis.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+		id, err := a.repo.GetIDBySlug(a.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			return &models.Article{}, nil
+		}
+		return (&Article{Service: a.Service, ID: id}).Get()
+	}
+
 	return article, nil
 }
 
 func (a *Article) GetAll() ([]*models.Article, error) {
-	var (
-		articles, cacheArticles []*models.Article
-	)
 	ctx := context.Background()
 
 	cache := cache_service.Article{
-		TagID: a.TagID,
-		State: a.State,
+		TagIDs:        a.TagIDs,
+		MatchAllTags:  a.MatchAllTags,
+		CategoryID:    a.CategoryID,
+		SubCategories: a.SubCategories,
+		State:         a.State,
 
 		PageNum:  a.PageNum,
 		PageSize: a.PageSize,
 	}
 
-	key := cache.GetArticlesKey()
-	exists, err := gredis.Exists(ctx, key)
+	version, err := gredis.GetVersion(ctx, cache.GetArticlesVersionKey())
 	if err != nil {
 		return nil, err
 	}
+	cache.Version = version
 
-	if exists {
-		data, err := gredis.Get(ctx, key)
+	return gredis.Fetch(ctx, cache.GetArticlesKey(), gredis.DefaultFetchOptions.Named("article_list"), func() ([]*models.Article, error) {
+		filter, err := a.getFilter()
 		if err != nil {
-			logging.Info(err)
-		} else {
-			json.Unmarshal(data, &cacheArticles)
-			return cacheArticles, nil
+			return nil, err
 		}
-	}
 
-	articles, err = models.GetArticles(a.PageNum, a.PageSize, a.GetMaps())
-	if err != nil {
-		return nil, err
-	}
-
-	gredis.Set(ctx, key, articles, 3600)
-	return articles, nil
+		return a.repo.List(a.PageNum, a.PageSize, a.GetMaps(), filter)
+	})
 }
 
 func (a *Article) GetMaps() map[string]interface{} {
@@ -159,21 +242,101 @@ func (a *Article) GetMaps() map[string]interface{} {
 		maps["state"] = a.State
 	}
 
-	if a.TagID != 0 {
-		maps["tag_id"] = a.TagID
+	return maps
+}
+
+// getFilter 构造按标签和分类过滤的条件
+func (a *Article) getFilter() (*models.ArticleFilter, error) {
+	filter := &models.ArticleFilter{
+		TagIDs:       a.TagIDs,
+		MatchAllTags: a.MatchAllTags,
 	}
 
-	return maps
+	if a.CategoryID > 0 {
+		filter.CategoryIDs = []int{a.CategoryID}
+		if a.SubCategories {
+			ids, err := a.categoryRepo.GetDescendantIDs(a.CategoryID)
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) > 0 {
+				filter.CategoryIDs = ids
+			}
+		}
+	}
+
+	return filter, nil
 }
 
 func (a *Article) Delete() error {
-	return models.DeleteArticle(a.ID)
+	if err := a.repo.Delete(a.ID); err != nil {
+		return err
+	}
+
+	clearCache(a.ID)
+	a.search.Remove(a.ID)
+	return nil
+}
+
+// Search 全文搜索文章标题和内容
+func (a *Article) Search(query string) ([]*search_service.Hit, int, error) {
+	return a.search.Search(query, a.State, a.PageNum, a.PageSize)
 }
 
 func (a *Article) Count() (int, error) {
-	return models.GetArticleTotal(a.GetMaps())
+	filter, err := a.getFilter()
+	if err != nil {
+		return 0, err
+	}
+
+	return a.repo.Count(a.GetMaps(), filter)
 }
 
 func (a *Article) ExistByID() (bool, error) {
-	return models.ExistArticleByID(a.ID)
+	return a.repo.ExistByID(a.ID)
+}
+
+// ExistBySlug 检查 slug 是否已被其他文章使用
+func (a *Article) ExistBySlug() (bool, error) {
+	return a.repo.ExistBySlug(a.Slug, a.ID)
+}
+
+// generateSlug 未指定 slug 时根据标题生成，重复时追加序号
+func (a *Article) generateSlug() (string, error) {
+	if a.Slug != "" {
+		return a.Slug, nil
+	}
+
+	base := util.Slugify(a.Title)
+	if base == "" {
+		base = "article"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		exists, err := a.repo.ExistBySlug(slug, 0)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// clearCache 删除文章缓存并使文章列表缓存失效。数据库已经写入成功，
+// 这里失败只记录日志，过期的缓存会在 TTL 到期后恢复一致
+func clearCache(ids ...int) {
+	if err := cache_service.InvalidateArticles(context.Background(), ids...); err != nil {
+		logging.Warn(err)
+	}
+}
+
+// clearSlugCache 删除 slug 的映射缓存，避免之前缓存的“不存在”结果挡住新使用该 slug 的文章
+func clearSlugCache(slug string) {
+	cache := cache_service.Article{Slug: slug}
+	if err := gredis.Delete(context.Background(), cache.GetArticleSlugKey()); err != nil {
+		logging.Warn(err)
+	}
 }
@@ -0,0 +1,63 @@
+package article_service
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/markdown"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+)
+
+const (
+	FORMAT_MARKDOWN = "markdown"
+	FORMAT_HTML     = "html"
+)
+
+// FormatPattern 校验 format 参数
+var FormatPattern = regexp.MustCompile("^(" + FORMAT_MARKDOWN + "|" + FORMAT_HTML + ")$")
+
+// RenderedArticle 内容已渲染为 HTML 的文章，Content 会覆盖 Article 中的 markdown 原文
+type RenderedArticle struct {
+	*models.Article
+
+	Content string              `json:"content"`
+	Toc     []*markdown.TocItem `json:"toc"`
+}
+
+// Render 将文章内容渲染为 HTML，渲染结果按文章ID和内容缓存
+func Render(article *models.Article) (*RenderedArticle, error) {
+	ctx := context.Background()
+	cache := cache_service.Article{ID: article.ID}
+	key := cache.GetRenderedKey(util.EncodeMD5(article.Content))
+
+	var result *markdown.Result
+	if data, err := gredis.Get(ctx, key); err == nil {
+		if err := json.Unmarshal(data, &result); err != nil {
+			logging.Info(err)
+			result = nil
+		}
+	}
+
+	if result == nil {
+		var err error
+		result, err = markdown.Render(article.Content)
+		if err != nil {
+			return nil, err
+		}
+		if err := gredis.Set(ctx, key, result, 24*time.Hour); err != nil && err != gredis.ErrNotConnected {
+			logging.Warn(err)
+		}
+	}
+
+	return &RenderedArticle{
+		Article: article,
+		Content: result.HTML,
+		Toc:     result.Toc,
+	}, nil
+}
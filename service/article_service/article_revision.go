@@ -0,0 +1,72 @@
+package article_service
+
+import (
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+)
+
+// RevisionDiff 两个文章版本之间各字段的行级差异
+type RevisionDiff struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Title   []util.DiffLine `json:"title"`
+	Desc    []util.DiffLine `json:"desc"`
+	Content []util.DiffLine `json:"content"`
+}
+
+func (a *Article) GetRevisions() ([]*models.ArticleRevision, error) {
+	return a.repo.GetRevisions(a.ID, a.PageNum, a.PageSize)
+}
+
+func (a *Article) CountRevisions() (int, error) {
+	return a.repo.CountRevisions(a.ID)
+}
+
+func (a *Article) GetRevision(revision int) (*models.ArticleRevision, error) {
+	return a.repo.GetRevision(a.ID, revision)
+}
+
+func (a *Article) ExistRevision(revision int) (bool, error) {
+	return a.repo.ExistRevision(a.ID, revision)
+}
+
+// DiffRevisions 计算从版本 from 到版本 to 的差异，差异过大时返回 util.ErrDiffTooLarge
+func (a *Article) DiffRevisions(from, to int) (*RevisionDiff, error) {
+	oldRev, err := a.repo.GetRevision(a.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	newRev, err := a.repo.GetRevision(a.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{From: from, To: to}
+	if diff.Title, err = util.DiffLines(oldRev.Title, newRev.Title); err != nil {
+		return nil, err
+	}
+	if diff.Desc, err = util.DiffLines(oldRev.Desc, newRev.Desc); err != nil {
+		return nil, err
+	}
+	if diff.Content, err = util.DiffLines(oldRev.Content, newRev.Content); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision 将文章恢复为指定版本的内容，恢复操作本身会产生一个新版本
+func (a *Article) RestoreRevision(revision int) error {
+	rev, err := a.repo.GetRevision(a.ID, revision)
+	if err != nil {
+		return err
+	}
+
+	if err := a.repo.RestoreRevision(a.ID, rev, a.ModifiedBy); err != nil {
+		return err
+	}
+
+	clearCache(a.ID)
+	a.search.Index(a.ID)
+	return nil
+}
@@ -0,0 +1,26 @@
+package article_service
+
+import (
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+)
+
+// PublishScheduled 发布所有到期的定时发布文章，并清理这些文章和文章列表的缓存
+func (s *Service) PublishScheduled() error {
+	ids, err := s.repo.PublishDue(time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	logging.Info("Published scheduled articles:", ids)
+
+	clearCache(ids...)
+	for _, id := range ids {
+		s.search.Index(id)
+	}
+	return nil
+}
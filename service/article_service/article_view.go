@@ -0,0 +1,164 @@
+package article_service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+	"github.com/redis/go-redis/v9"
+)
+
+// AddView 记录一次文章浏览，同一访客在去重窗口内只计一次，返回尚未写回数据库的浏览量。
+// 未连接 Redis 时不计数
+func (a *Article) AddView(visitor string) (int, error) {
+	if gredis.RedisClient == nil {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	cache := cache_service.Article{ID: a.ID}
+	field := strconv.Itoa(a.ID)
+
+	first, err := gredis.RedisClient.SetNX(ctx, cache.GetViewerKey(visitor), 1, setting.Current().App.ViewDedupWindow).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if !first {
+		pending, err := gredis.RedisClient.HGet(ctx, cache.GetViewsKey(), field).Int()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		return pending, nil
+	}
+
+	pending, err := gredis.RedisClient.HIncrBy(ctx, cache.GetViewsKey(), field, 1).Result()
+	if err != nil {
+		return 0, err
+	}
+	return int(pending), nil
+}
+
+// 待写回的浏览量存在时将其整体转移到本次写回专用的键，返回是否发生了转移
+var claimViewsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+return 1
+`)
+
+// flushLockTTL 写回锁的有效期，持有锁的进程异常退出后，遗留的浏览量在锁过期后由之后的写回处理
+const flushLockTTL = 5 * time.Minute
+
+// FlushViews 将 Redis 中累计的浏览量批量写回数据库。
+// 每次写回把当前计数转移到带唯一后缀的键，避免与新的计数以及其他进程（例如平滑重启时新旧进程）的写回冲突；
+// 之前写回失败或删除失败遗留的键会在之后的写回中先行处理
+func (s *Service) FlushViews() error {
+	// 没有 Redis 时不会记录浏览量，也就没有需要写回的数据
+	if gredis.RedisClient == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	cache := cache_service.Article{}
+
+	if err := s.drainFlushingViews(ctx); err != nil {
+		return err
+	}
+
+	id, err := util.RandomID()
+	if err != nil {
+		return err
+	}
+
+	// 先持有锁再转移计数，其他进程看到该键时不会将其当作遗留数据处理
+	if _, err := gredis.SetNX(ctx, cache.GetFlushViewsLockKey(id), 1, flushLockTTL); err != nil {
+		return err
+	}
+
+	keys := []string{cache.GetViewsKey(), cache.GetFlushingViewsKey(id)}
+	claimed, err := claimViewsScript.Run(ctx, gredis.RedisClient, keys).Bool()
+	if err != nil || !claimed {
+		if err := gredis.Delete(ctx, cache.GetFlushViewsLockKey(id)); err != nil {
+			logging.Warn(err)
+		}
+		return err
+	}
+
+	return s.flushViews(ctx, id)
+}
+
+// drainFlushingViews 写回之前遗留且没有进程持有锁的浏览量
+func (s *Service) drainFlushingViews(ctx context.Context) error {
+	cache := cache_service.Article{}
+	prefix := cache.GetFlushingViewsKey("")
+
+	iter := gredis.RedisClient.Scan(ctx, 0, cache.GetFlushingViewsKey("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		id := strings.TrimPrefix(iter.Val(), prefix)
+		locked, err := gredis.SetNX(ctx, cache.GetFlushViewsLockKey(id), 1, flushLockTTL)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			continue
+		}
+
+		if err := s.flushViews(ctx, id); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// flushViews 将 id 对应的浏览量写回数据库后删除，调用方需已持有该批浏览量的锁
+func (s *Service) flushViews(ctx context.Context, id string) error {
+	cache := cache_service.Article{}
+	key, lockKey := cache.GetFlushingViewsKey(id), cache.GetFlushViewsLockKey(id)
+
+	data, err := gredis.RedisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	views := make(map[int]int, len(data))
+	for field, value := range data {
+		articleID, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			continue
+		}
+		views[articleID] = n
+	}
+
+	if len(views) > 0 {
+		if err := s.repo.AddViews(views); err != nil {
+			// 释放锁，下一次写回时重试
+			if err := gredis.Delete(ctx, lockKey); err != nil {
+				logging.Warn(err)
+			}
+			return err
+		}
+
+		// 缓存中的文章仍是写回之前的浏览量，而待写回的计数已经清零，删除缓存避免显示的浏览量回退
+		ids := make([]int, 0, len(views))
+		for articleID := range views {
+			ids = append(ids, articleID)
+		}
+		if err := cache_service.DeleteArticles(ctx, ids...); err != nil {
+			logging.Warn(err)
+		}
+	}
+
+	return gredis.Deletes(ctx, key, lockKey)
+}
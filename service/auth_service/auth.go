@@ -1,12 +1,124 @@
 package auth_service
 
-import "github.com/3Eeeecho/go-gin-example/models"
+import (
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+)
 
+// Service 用户服务依赖的仓库，由 NewService 注入
+type Service struct {
+	repo models.UserRepository
+}
+
+func NewService(users models.UserRepository) *Service {
+	return &Service{repo: users}
+}
+
+// Auth 一次用户操作的参数，Service 必须设置
 type Auth struct {
-	Username string
-	Password string
+	*Service
+
+	ID          int
+	Username    string
+	Password    string
+	NewPassword string
+	Role        string
 }
 
+// Check 校验用户名和密码，校验成功后会填充 ID 和 Role。
+// 旧的明文密码会被重新哈希保存，但要等 password 列加宽之后，否则哈希会写入失败或被截断
 func (a *Auth) Check() (bool, error) {
-	return models.CheckUser(a.Username, a.Password)
+	user, err := a.repo.GetByUsername(a.Username)
+	if err != nil {
+		return false, err
+	}
+
+	if user.ID == 0 || !util.ComparePassword(user.Password, a.Password) {
+		return false, nil
+	}
+
+	a.ID = user.ID
+	a.Role = user.Role
+	if a.Role == "" {
+		a.Role = models.ROLE_READER
+	}
+
+	if !util.IsHashedPassword(user.Password) {
+		ok, err := a.repo.CanStorePasswordHash()
+		if err != nil {
+			logging.Warn("check password column failed:", err)
+			return true, nil
+		}
+		if !ok {
+			return true, nil
+		}
+
+		hash, err := util.HashPassword(a.Password)
+		if err != nil {
+			logging.Warn("rehash legacy password failed:", err)
+			return true, nil
+		}
+		if err := a.repo.UpdatePassword(user.ID, hash); err != nil {
+			logging.Warn("rehash legacy password failed:", err)
+		}
+	}
+
+	return true, nil
+}
+
+func (a *Auth) Get() (*models.User, error) {
+	return a.repo.GetByUsername(a.Username)
+}
+
+func (a *Auth) ExistByUsername() (bool, error) {
+	return a.repo.ExistByUsername(a.Username)
+}
+
+// Register 注册新用户，新用户默认为读者角色
+func (a *Auth) Register() error {
+	hash, err := util.HashPassword(a.Password)
+	if err != nil {
+		return err
+	}
+	return a.repo.Add(a.Username, hash, models.ROLE_READER)
+}
+
+func (a *Auth) ExistByID() (bool, error) {
+	return a.repo.ExistByID(a.ID)
+}
+
+// SetRole 修改用户角色，并吊销用户此前签发的 token，使旧角色的权限不再有效。
+// 先吊销再修改，吊销失败时角色保持不变
+func (a *Auth) SetRole() error {
+	if err := RevokeUserTokens(a.ID); err != nil {
+		return err
+	}
+	return a.repo.UpdateRole(a.ID, a.Role)
+}
+
+// ChangePassword 校验旧密码后修改为新密码，并吊销用户此前签发的全部 token（包括其他会话的 refresh token），
+// 旧密码错误时返回 false。先吊销再修改，吊销失败时密码保持不变，避免修改成功却返回错误
+func (a *Auth) ChangePassword() (bool, error) {
+	user, err := a.repo.GetByUsername(a.Username)
+	if err != nil {
+		return false, err
+	}
+
+	if user.ID == 0 || !util.ComparePassword(user.Password, a.Password) {
+		return false, nil
+	}
+
+	hash, err := util.HashPassword(a.NewPassword)
+	if err != nil {
+		return false, err
+	}
+
+	if err := RevokeUserTokens(user.ID); err != nil {
+		return false, err
+	}
+	if err := a.repo.UpdatePassword(user.ID, hash); err != nil {
+		return false, err
+	}
+	return true, nil
 }
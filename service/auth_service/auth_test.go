@@ -0,0 +1,75 @@
+package auth_service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+)
+
+// legacyUserRepository 模拟旧版用户表：严格模式下超过 password 列宽度的写入会失败
+type legacyUserRepository struct {
+	models.UserRepository
+	width   int
+	widened bool
+}
+
+func (r *legacyUserRepository) UpdatePassword(id int, password string) error {
+	if len(password) > r.width {
+		return fmt.Errorf("data too long for column 'password' (%d > %d)", len(password), r.width)
+	}
+	return r.UserRepository.UpdatePassword(id, password)
+}
+
+func (r *legacyUserRepository) CanStorePasswordHash() (bool, error) {
+	return r.widened, nil
+}
+
+func TestCheckRehashesLegacyPassword(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		widened  bool
+		wantHash bool
+	}{
+		{name: "legacy column", widened: false, wantHash: false},
+		{name: "widened column", widened: true, wantHash: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repos := models.NewMemoryRepositories()
+			if err := repos.Users.Add("legacy", "plaintext", models.ROLE_AUTHOR); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			width := 50
+			if tc.widened {
+				width = 100
+			}
+			service := NewService(&legacyUserRepository{UserRepository: repos.Users, width: width, widened: tc.widened})
+
+			auth := Auth{Service: service, Username: "legacy", Password: "plaintext"}
+			ok, err := auth.Check()
+			if err != nil || !ok {
+				t.Fatalf("Check = %v, %v, want true", ok, err)
+			}
+			if auth.Role != models.ROLE_AUTHOR {
+				t.Errorf("Role = %q, want %q", auth.Role, models.ROLE_AUTHOR)
+			}
+
+			user, err := repos.Users.GetByUsername("legacy")
+			if err != nil {
+				t.Fatalf("GetByUsername: %v", err)
+			}
+			if hashed := util.IsHashedPassword(user.Password); hashed != tc.wantHash {
+				t.Errorf("password hashed = %v, want %v", hashed, tc.wantHash)
+			}
+
+			// 无论是否重新哈希，原密码都可以继续登录
+			ok, err = (&Auth{Service: service, Username: "legacy", Password: "plaintext"}).Check()
+			if err != nil || !ok {
+				t.Errorf("second Check = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package auth_service
+
+import (
+	"context"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+)
+
+// LockedFor 返回账号剩余的锁定时间，未锁定时为 0
+func (a *Auth) LockedFor() (time.Duration, error) {
+	if setting.Current().RateLimit.LoginMaxFailures <= 0 {
+		return 0, nil
+	}
+	cache := cache_service.Login{Username: a.Username}
+	return gredis.TTL(context.Background(), cache.GetLockKey())
+}
+
+// RecordFailure 记录一次登录失败，时间窗口内失败次数达到上限时锁定账号并返回锁定时长。
+// 按用户名计数而不区分用户是否存在，避免通过锁定行为探测用户名
+func (a *Auth) RecordFailure() (time.Duration, error) {
+	cfg := setting.Current().RateLimit
+	if cfg.LoginMaxFailures <= 0 {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	cache := cache_service.Login{Username: a.Username}
+	n, err := gredis.Incr(ctx, cache.GetFailuresKey(), cfg.LoginFailureWindow)
+	if err != nil {
+		return 0, err
+	}
+	if n < int64(cfg.LoginMaxFailures) {
+		return 0, nil
+	}
+
+	if err := gredis.Set(ctx, cache.GetLockKey(), 1, cfg.LoginLockout); err != nil {
+		return 0, err
+	}
+	return cfg.LoginLockout, gredis.Delete(ctx, cache.GetFailuresKey())
+}
+
+// ResetFailures 登录成功后清除失败次数
+func (a *Auth) ResetFailures() error {
+	cache := cache_service.Login{Username: a.Username}
+	return gredis.Delete(context.Background(), cache.GetFailuresKey())
+}
@@ -0,0 +1,94 @@
+package auth_service
+
+import (
+	"context"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/util"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+)
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // access token 有效期，单位秒
+}
+
+// NewTokenPair 为用户签发 access token 和 refresh token
+func NewTokenPair(userID int, username, role string) (*TokenPair, error) {
+	token, err := util.GenerateToken(userID, username, role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := util.GenerateRefreshToken(userID, username, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(setting.AppSetting.JwtAccessExpire / time.Second),
+	}, nil
+}
+
+// RevokeToken 将 token 的 jti 加入 Redis 黑名单，黑名单在 token 过期后自动清除
+func RevokeToken(claims *util.Claims) error {
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0))
+	if ttl <= 0 {
+		return nil
+	}
+
+	cache := cache_service.Token{ID: claims.Id}
+	return gredis.Set(context.Background(), cache.GetDenyKey(), 1, ttl)
+}
+
+// ClaimToken 原子地吊销 token，返回 false 表示 token 已被吊销或已过期。
+// refresh token 通过它保证只能使用一次：并发的刷新请求中只有一个能占用 jti
+func ClaimToken(claims *util.Claims) (bool, error) {
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0))
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	cache := cache_service.Token{ID: claims.Id}
+	return gredis.SetNX(context.Background(), cache.GetDenyKey(), 1, ttl)
+}
+
+// RevokeUserTokens 吊销用户在此之前签发的全部 token，吊销时间精确到毫秒，记录在 refresh token 的最长有效期后自动清除。
+// 返回时当前时间已晚于吊销时间，随后签发的 token 不会被吊销
+func RevokeUserTokens(userID int) error {
+	notBefore := time.Now().UnixMilli()
+	cache := cache_service.Token{UserID: userID}
+	if err := gredis.Set(context.Background(), cache.GetNotBeforeKey(), notBefore, setting.AppSetting.JwtRefreshExpire); err != nil {
+		return err
+	}
+
+	if wait := time.Until(time.UnixMilli(notBefore + 1)); wait > 0 {
+		time.Sleep(wait)
+	}
+	return nil
+}
+
+// IsTokenRevoked 检查 token 是否已被吊销
+func IsTokenRevoked(claims *util.Claims) (bool, error) {
+	ctx := context.Background()
+	cache := cache_service.Token{ID: claims.Id, UserID: claims.UserID}
+	revoked, err := gredis.Exists(ctx, cache.GetDenyKey())
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	notBefore, err := gredis.GetInt64(ctx, cache.GetNotBeforeKey())
+	if err != nil || notBefore == 0 {
+		return false, err
+	}
+	// 旧版本以秒记录吊销时间，早于该秒签发的 token 才被吊销
+	if notBefore < 1e12 {
+		notBefore = notBefore*1000 - 1
+	}
+	return claims.IssuedAtMilli() <= notBefore, nil
+}
@@ -8,9 +8,14 @@ import (
 )
 
 type Article struct {
-	ID    int
-	TagID int
-	State int
+	ID            int
+	Slug          string
+	TagIDs        []int
+	MatchAllTags  bool
+	CategoryID    int
+	SubCategories bool
+	State         int
+	Version       string // 文章列表缓存的版本，见 GetArticlesVersionKey
 
 	PageNum  int
 	PageSize int
@@ -20,17 +25,74 @@ func (a *Article) GetArticleKey() string {
 	return e.CACHE_ARTICLE + "_" + strconv.Itoa(a.ID)
 }
 
+// GetArticleSlugKey slug 到文章ID的映射缓存键
+func (a *Article) GetArticleSlugKey() string {
+	return e.CACHE_ARTICLE + "_SLUG_" + a.Slug
+}
+
+// GetRenderedKey 文章渲染结果的缓存键，包含内容的哈希，内容变化后旧的渲染结果自然失效。
+// 不使用修改时间，它只精确到秒，同一秒内的多次修改会读到旧的渲染结果
+func (a *Article) GetRenderedKey(contentHash string) string {
+	return e.CACHE_ARTICLE + "_" + strconv.Itoa(a.ID) + "_HTML_" + contentHash
+}
+
+// GetViewsKey 待写回数据库的文章浏览量，Hash 结构，field 为文章ID
+func (a *Article) GetViewsKey() string {
+	return e.CACHE_ARTICLE + "_VIEWS"
+}
+
+// GetFlushingViewsKey 正在写回数据库的文章浏览量，id 为每次写回唯一的标识，传入 "*" 时为匹配全部的模式
+func (a *Article) GetFlushingViewsKey(id string) string {
+	return e.CACHE_ARTICLE + "_VIEWS_FLUSHING_" + id
+}
+
+// GetFlushViewsLockKey 写回 id 对应浏览量的锁，持有锁的进程负责写回并删除该批浏览量
+func (a *Article) GetFlushViewsLockKey(id string) string {
+	return e.CACHE_ARTICLE + "_VIEWS_FLUSH_LOCK_" + id
+}
+
+// GetViewerKey 访客在去重窗口内是否已浏览过文章
+func (a *Article) GetViewerKey(visitor string) string {
+	return e.CACHE_ARTICLE + "_" + strconv.Itoa(a.ID) + "_VIEWER_" + visitor
+}
+
+// GetArticleVersionKey 单篇文章缓存的版本号，删除单篇缓存时更新，
+// 删除之前开始的后台刷新因版本不一致不会再写回旧数据
+func (a *Article) GetArticleVersionKey() string {
+	return e.CACHE_ARTICLE + "_VERSION"
+}
+
+// GetArticlesVersionKey 文章列表缓存的版本号，任何文章写入都会更新版本，使全部列表缓存失效
+func (a *Article) GetArticlesVersionKey() string {
+	return e.CACHE_ARTICLE + "_LIST_VERSION"
+}
+
 func (a *Article) GetArticlesKey() string {
 	keys := []string{
 		e.CACHE_ARTICLE,
 		"LIST",
+		"V" + a.Version,
 	}
 
 	if a.ID > 0 {
 		keys = append(keys, strconv.Itoa(a.ID))
 	}
-	if a.TagID > 0 {
-		keys = append(keys, strconv.Itoa(a.TagID))
+	if len(a.TagIDs) > 0 {
+		tags := make([]string, 0, len(a.TagIDs))
+		for _, id := range a.TagIDs {
+			tags = append(tags, strconv.Itoa(id))
+		}
+		mode := "ANY"
+		if a.MatchAllTags {
+			mode = "ALL"
+		}
+		keys = append(keys, "TAGS", mode, strings.Join(tags, ","))
+	}
+	if a.CategoryID > 0 {
+		keys = append(keys, "CATEGORY", strconv.Itoa(a.CategoryID))
+		if a.SubCategories {
+			keys = append(keys, "SUB")
+		}
 	}
 	if a.State >= 0 {
 		keys = append(keys, strconv.Itoa(a.State))
@@ -0,0 +1,33 @@
+package cache_service
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+)
+
+type Comment struct {
+	ID        int
+	ArticleID int
+	State     int
+
+	PageNum  int
+	PageSize int
+}
+
+// GetCountKey 文章已通过审核的评论数缓存键
+func (c *Comment) GetCountKey() string {
+	return e.CACHE_ARTICLE + "_" + strconv.Itoa(c.ArticleID) + "_" + e.CACHE_COMMENT + "_COUNT"
+}
+
+func (c *Comment) GetCommentsKey() string {
+	keys := []string{
+		e.CACHE_ARTICLE,
+		strconv.Itoa(c.ArticleID),
+		e.CACHE_COMMENT,
+		"LIST",
+	}
+
+	return strings.Join(keys, "_")
+}
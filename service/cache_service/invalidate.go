@@ -0,0 +1,47 @@
+package cache_service
+
+import (
+	"context"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+)
+
+// DeleteArticles 删除指定文章的单篇缓存，并更新单篇缓存的版本
+func DeleteArticles(ctx context.Context, ids ...int) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		cache := Article{ID: id}
+		keys = append(keys, cache.GetArticleKey())
+	}
+	if err := gredis.Deletes(ctx, keys...); err != nil {
+		return err
+	}
+
+	cache := Article{}
+	return gredis.BumpVersion(ctx, cache.GetArticleVersionKey())
+}
+
+// InvalidateArticles 删除指定文章的单篇缓存，并使全部文章列表缓存失效
+func InvalidateArticles(ctx context.Context, ids ...int) error {
+	if err := DeleteArticles(ctx, ids...); err != nil {
+		return err
+	}
+
+	cache := Article{}
+	return gredis.BumpVersion(ctx, cache.GetArticlesVersionKey())
+}
+
+// InvalidateTags 删除指定标签的单个缓存，并使全部标签列表缓存失效
+func InvalidateTags(ctx context.Context, ids ...int) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		cache := Tag{ID: id}
+		keys = append(keys, cache.GetTagKey())
+	}
+	if err := gredis.Deletes(ctx, keys...); err != nil {
+		return err
+	}
+
+	cache := Tag{}
+	return gredis.BumpVersion(ctx, cache.GetTagsVersionKey())
+}
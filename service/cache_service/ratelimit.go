@@ -0,0 +1,27 @@
+package cache_service
+
+import "github.com/3Eeeecho/go-gin-example/pkg/e"
+
+type RateLimit struct {
+	Group string // 路由分组，如 auth、api
+	Key   string // 限流维度的值，如 ip:127.0.0.1
+}
+
+// GetKey 限流计数的键，有序集合结构
+func (r *RateLimit) GetKey() string {
+	return e.CACHE_LIMIT + "_" + r.Group + "_" + r.Key
+}
+
+type Login struct {
+	Username string
+}
+
+// GetFailuresKey 登录失败次数的键
+func (l *Login) GetFailuresKey() string {
+	return e.CACHE_LOGIN + "_FAILURES_" + l.Username
+}
+
+// GetLockKey 账号锁定的键，存在即表示锁定中
+func (l *Login) GetLockKey() string {
+	return e.CACHE_LOGIN + "_LOCK_" + l.Username
+}
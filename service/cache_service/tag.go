@@ -8,9 +8,10 @@ import (
 )
 
 type Tag struct {
-	ID    int
-	Name  string
-	State int
+	ID      int
+	Name    string
+	State   int
+	Version string // 标签列表缓存的版本，见 GetTagsVersionKey
 
 	PageNum  int
 	PageSize int
@@ -20,10 +21,16 @@ func (t *Tag) GetTagKey() string {
 	return e.CACHE_TAG + "_" + strconv.Itoa(t.ID)
 }
 
+// GetTagsVersionKey 标签列表缓存的版本号，任何标签写入都会更新版本，使全部列表缓存失效
+func (t *Tag) GetTagsVersionKey() string {
+	return e.CACHE_TAG + "_LIST_VERSION"
+}
+
 func (t *Tag) GetTagsKey() string {
 	keys := []string{
 		e.CACHE_TAG,
 		"LIST",
+		"V" + t.Version,
 	}
 
 	if t.Name != "" {
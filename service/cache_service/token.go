@@ -0,0 +1,22 @@
+package cache_service
+
+import (
+	"strconv"
+
+	"github.com/3Eeeecho/go-gin-example/pkg/e"
+)
+
+type Token struct {
+	ID     string // JWT 的 jti
+	UserID int
+}
+
+// GetDenyKey 已吊销 token 的黑名单键
+func (t *Token) GetDenyKey() string {
+	return e.CACHE_TOKEN + "_DENY_" + t.ID
+}
+
+// GetNotBeforeKey 用户 token 的吊销时间键，Unix 毫秒，不晚于该时间签发的 token 均视为已吊销
+func (t *Token) GetNotBeforeKey() string {
+	return e.CACHE_TOKEN + "_NOT_BEFORE_" + strconv.Itoa(t.UserID)
+}
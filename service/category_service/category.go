@@ -0,0 +1,213 @@
+package category_service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+)
+
+// Service 分类服务依赖的仓库，由 NewService 注入
+type Service struct {
+	repo models.CategoryRepository
+}
+
+func NewService(categories models.CategoryRepository) *Service {
+	return &Service{repo: categories}
+}
+
+// Category 一次分类操作的参数，Service 必须设置
+type Category struct {
+	*Service
+
+	ID         int
+	Name       string
+	ParentID   int
+	CreatedBy  string
+	ModifiedBy string
+	State      int
+}
+
+func (c *Category) ExistByID() (bool, error) {
+	return c.repo.ExistByID(c.ID)
+}
+
+// ExistByName 检查同一父分类下是否已存在同名分类
+func (c *Category) ExistByName() (bool, error) {
+	return c.repo.ExistByName(c.Name, c.ParentID, c.ID)
+}
+
+func (c *Category) Get() (*models.Category, error) {
+	return c.repo.Get(c.ID)
+}
+
+func (c *Category) Add() error {
+	parentPath := ""
+	if c.ParentID > 0 {
+		parent, err := c.repo.Get(c.ParentID)
+		if err != nil {
+			return err
+		}
+		parentPath = parent.Path
+	}
+
+	return c.repo.Add(c.Name, c.ParentID, parentPath, c.State, c.CreatedBy)
+}
+
+func (c *Category) Edit() error {
+	data := make(map[string]interface{})
+	data["modified_by"] = c.ModifiedBy
+	data["name"] = c.Name
+	if c.State >= 0 {
+		data["state"] = c.State
+	}
+	if err := c.repo.Edit(c.ID, data); err != nil {
+		return err
+	}
+
+	c.clearArticlesCache()
+	return nil
+}
+
+// CanMoveTo 检查分类能否移动到 ParentID 下，目标为自身或子孙分类时会形成环
+func (c *Category) CanMoveTo() (bool, error) {
+	if c.ParentID == 0 {
+		return true, nil
+	}
+	if c.ParentID == c.ID {
+		return false, nil
+	}
+
+	category, err := c.repo.Get(c.ID)
+	if err != nil {
+		return false, err
+	}
+	parent, err := c.repo.Get(c.ParentID)
+	if err != nil {
+		return false, err
+	}
+
+	return !strings.HasPrefix(parent.Path, category.Path), nil
+}
+
+// Move 将分类移动到 ParentID 下，调用前需要通过 CanMoveTo 检查
+func (c *Category) Move() error {
+	parent := &models.Category{}
+	if c.ParentID > 0 {
+		var err error
+		parent, err = c.repo.Get(c.ParentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.repo.Move(c.ID, parent, c.ModifiedBy); err != nil {
+		return err
+	}
+
+	c.clearArticlesCache()
+	return nil
+}
+
+// HasChildren 检查分类下是否还有子分类
+func (c *Category) HasChildren() (bool, error) {
+	count, err := c.repo.CountChildren(c.ID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (c *Category) Delete() error {
+	// 删除后文章会被置为未分类，需要先取得受影响的文章
+	articleIDs, err := c.repo.GetArticleIDs([]int{c.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := c.repo.Delete(c.ID); err != nil {
+		return err
+	}
+
+	clearCache(articleIDs)
+	return nil
+}
+
+// GetTree 获取分类树，State 为 -1 时返回全部分类。
+// 按状态过滤后父分类可能不在结果中，此时分类挂到最近的仍在结果中的祖先下，没有这样的祖先则作为顶级分类
+func (c *Category) GetTree() ([]*models.Category, error) {
+	maps := make(map[string]interface{})
+	if c.State >= 0 {
+		maps["state"] = c.State
+	}
+
+	categories, err := c.repo.List(maps)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*models.Category, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = category
+	}
+
+	roots := make([]*models.Category, 0)
+	for _, category := range categories {
+		if parent := nearestAncestor(nodes, category); parent != nil {
+			parent.Children = append(parent.Children, category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	return roots, nil
+}
+
+// nearestAncestor 按路径从近到远查找分类在 nodes 中的祖先，找不到时返回 nil
+func nearestAncestor(nodes map[int]*models.Category, category *models.Category) *models.Category {
+	if parent, ok := nodes[category.ParentID]; ok {
+		return parent
+	}
+
+	ids := strings.Split(strings.Trim(category.Path, "/"), "/")
+	for i := len(ids) - 2; i >= 0; i-- {
+		id, err := strconv.Atoi(ids[i])
+		if err != nil {
+			continue
+		}
+		if parent, ok := nodes[id]; ok {
+			return parent
+		}
+	}
+	return nil
+}
+
+// clearArticlesCache 清理属于该分类及其子孙分类的文章的缓存，并使文章列表缓存失效：
+// 文章和列表中都包含所属分类，分类移动后整棵子树的路径以及包含子分类的过滤结果都会变化。失败只记录日志
+func (c *Category) clearArticlesCache() {
+	categoryIDs, err := c.repo.GetDescendantIDs(c.ID)
+	if err != nil {
+		logging.Warn(err)
+		clearCache(nil)
+		return
+	}
+
+	articleIDs, err := c.repo.GetArticleIDs(categoryIDs)
+	if err != nil {
+		logging.Warn(err)
+		clearCache(nil)
+		return
+	}
+
+	clearCache(articleIDs)
+}
+
+// clearCache 删除文章缓存并使文章列表缓存失效，失败只记录日志
+func clearCache(articleIDs []int) {
+	if err := cache_service.InvalidateArticles(context.Background(), articleIDs...); err != nil {
+		logging.Warn(err)
+	}
+}
@@ -0,0 +1,223 @@
+package comment_service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/service/cache_service"
+)
+
+// Service 评论服务依赖的仓库，由 NewService 注入
+type Service struct {
+	repo models.CommentRepository
+}
+
+func NewService(comments models.CommentRepository) *Service {
+	return &Service{repo: comments}
+}
+
+// Comment 一次评论操作的参数，Service 必须设置
+type Comment struct {
+	*Service
+
+	ID         int
+	ArticleID  int
+	ParentID   int
+	Content    string
+	CreatedBy  string
+	ModifiedBy string
+	State      int
+
+	PageNum  int
+	PageSize int
+}
+
+func (c *Comment) Add() error {
+	comment := map[string]interface{}{
+		"article_id": c.ArticleID,
+		"parent_id":  c.ParentID,
+		"content":    c.Content,
+		"created_by": c.CreatedBy,
+	}
+
+	return c.repo.Add(comment)
+}
+
+// Edit 修改评论内容，修改后的评论需要重新审核
+func (c *Comment) Edit() error {
+	data := map[string]interface{}{
+		"content":     c.Content,
+		"modified_by": c.ModifiedBy,
+		"state":       models.COMMENT_STATE_PENDING,
+	}
+
+	if err := c.repo.Edit(c.ID, data); err != nil {
+		return err
+	}
+
+	c.clearCache()
+	return nil
+}
+
+// Audit 审核评论，state 为 models.COMMENT_STATE_APPROVED 或 models.COMMENT_STATE_REJECTED
+func (c *Comment) Audit() error {
+	data := map[string]interface{}{
+		"state":       c.State,
+		"modified_by": c.ModifiedBy,
+	}
+
+	if err := c.repo.Edit(c.ID, data); err != nil {
+		return err
+	}
+
+	c.clearCache()
+	return nil
+}
+
+// Delete 删除评论以及它下面的所有回复
+func (c *Comment) Delete() error {
+	comments, err := c.repo.List(0, 0, map[string]interface{}{
+		"article_id": c.ArticleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	children := make(map[int][]int)
+	for _, comment := range comments {
+		children[comment.ParentID] = append(children[comment.ParentID], comment.ID)
+	}
+
+	ids := []int{c.ID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+
+	if err := c.repo.Delete(ids); err != nil {
+		return err
+	}
+
+	c.clearCache()
+	return nil
+}
+
+func (c *Comment) Get() (*models.Comment, error) {
+	return c.repo.Get(c.ID)
+}
+
+func (c *Comment) ExistByID() (bool, error) {
+	return c.repo.ExistByID(c.ID)
+}
+
+// GetTree 获取文章下已通过审核的评论，并按父子关系组装成树
+func (c *Comment) GetTree() ([]*models.Comment, error) {
+	var comments []*models.Comment
+
+	ctx := context.Background()
+	cache := cache_service.Comment{ArticleID: c.ArticleID}
+	key := cache.GetCommentsKey()
+
+	if exists, _ := gredis.Exists(ctx, key); exists {
+		data, err := gredis.Get(ctx, key)
+		if err != nil {
+			logging.Info(err)
+		} else if err = json.Unmarshal(data, &comments); err == nil {
+			return buildTree(comments), nil
+		}
+	}
+
+	comments, err := c.repo.List(0, 0, map[string]interface{}{
+		"article_id": c.ArticleID,
+		"state":      models.COMMENT_STATE_APPROVED,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gredis.Set(ctx, key, comments, time.Hour)
+	return buildTree(comments), nil
+}
+
+// CountApproved 统计文章下已通过审核的评论数，结果缓存在 Redis 中
+func (c *Comment) CountApproved() (int, error) {
+	ctx := context.Background()
+	cache := cache_service.Comment{ArticleID: c.ArticleID}
+	key := cache.GetCountKey()
+
+	if data, err := gredis.Get(ctx, key); err == nil {
+		if count, err := strconv.Atoi(string(data)); err == nil {
+			return count, nil
+		}
+	}
+
+	count, err := c.repo.Count(map[string]interface{}{
+		"article_id": c.ArticleID,
+		"state":      models.COMMENT_STATE_APPROVED,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	gredis.Set(ctx, key, count, time.Hour)
+	return count, nil
+}
+
+// GetAll 按条件分页获取评论（不组装树），用于审核队列
+func (c *Comment) GetAll() ([]*models.Comment, error) {
+	return c.repo.List(c.PageNum, c.PageSize, c.getMaps())
+}
+
+func (c *Comment) Count() (int, error) {
+	return c.repo.Count(c.getMaps())
+}
+
+func (c *Comment) getMaps() map[string]interface{} {
+	maps := make(map[string]interface{})
+
+	if c.ArticleID > 0 {
+		maps["article_id"] = c.ArticleID
+	}
+	if c.State >= 0 {
+		maps["state"] = c.State
+	}
+
+	return maps
+}
+
+func (c *Comment) clearCache() {
+	ctx := context.Background()
+	cache := cache_service.Comment{ArticleID: c.ArticleID}
+
+	for _, key := range []string{cache.GetCommentsKey(), cache.GetCountKey()} {
+		if err := gredis.Delete(ctx, key); err != nil {
+			logging.Warn(err)
+		}
+	}
+}
+
+// buildTree 将扁平的评论列表组装成树，父评论不在列表中的回复会被丢弃
+func buildTree(comments []*models.Comment) []*models.Comment {
+	nodes := make(map[int]*models.Comment, len(comments))
+	for _, comment := range comments {
+		comment.Children = nil
+		nodes[comment.ID] = comment
+	}
+
+	roots := make([]*models.Comment, 0)
+	for _, comment := range comments {
+		if comment.ParentID == 0 {
+			roots = append(roots, comment)
+			continue
+		}
+		if parent, ok := nodes[comment.ParentID]; ok {
+			parent.Children = append(parent.Children, comment)
+		}
+	}
+
+	return roots
+}
@@ -0,0 +1,118 @@
+package health_service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/export"
+	"github.com/3Eeeecho/go-gin-example/pkg/gredis"
+	"github.com/3Eeeecho/go-gin-example/pkg/qrcode"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+	"github.com/3Eeeecho/go-gin-example/pkg/upload"
+)
+
+const (
+	STATUS_UP   = "up"
+	STATUS_DOWN = "down"
+)
+
+// 单个依赖检查的超时时间
+const checkTimeout = 2 * time.Second
+
+// Check 单个依赖的检查结果
+type Check struct {
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Report 就绪检查的汇总结果
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]*Check `json:"checks"`
+}
+
+// checkers 就绪检查需要检查的依赖
+var checkers = map[string]func(ctx context.Context) error{
+	"database":    models.Ping,
+	"redis":       gredis.Ping,
+	"runtime_dir": checkRuntimeDirs,
+}
+
+// Ready 并发检查所有依赖，全部可用时 Status 为 up
+func Ready() *Report {
+	report := &Report{
+		Status: STATUS_UP,
+		Checks: make(map[string]*Check, len(checkers)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker func(ctx context.Context) error) {
+			defer wg.Done()
+			check := run(checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = check
+			if check.Status != STATUS_UP {
+				report.Status = STATUS_DOWN
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func run(checker func(ctx context.Context) error) *Check {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker(ctx)
+	check := &Check{
+		Status:  STATUS_UP,
+		Latency: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = STATUS_DOWN
+		check.Error = err.Error()
+	}
+
+	return check
+}
+
+// checkRuntimeDirs 检查上传、导出、二维码和日志目录是否可写
+func checkRuntimeDirs(ctx context.Context) error {
+	dirs := []string{
+		upload.GetImageFullPath(),
+		export.GetExcelFullPath(),
+		qrcode.GetQrCodeFullPath(),
+		setting.AppSetting.RuntimeRootPath + setting.AppSetting.LogSavePath,
+	}
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	return nil
+}
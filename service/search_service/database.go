@@ -0,0 +1,44 @@
+package search_service
+
+import "github.com/3Eeeecho/go-gin-example/models"
+
+// DatabaseEngine 使用数据库自身全文检索能力的搜索引擎：MySQL 使用 ngram FULLTEXT 索引，
+// PostgreSQL 使用 tsvector，SQLite 退化为 LIKE 匹配。索引由 0005 迁移建立
+type DatabaseEngine struct {
+	articles models.ArticleSearcher
+}
+
+// NewDatabaseEngine 返回通过 articles 搜索的引擎
+func NewDatabaseEngine(articles models.ArticleSearcher) *DatabaseEngine {
+	return &DatabaseEngine{articles: articles}
+}
+
+// Index 由数据库自动维护索引，无需处理
+func (m *DatabaseEngine) Index(article *models.Article) error {
+	return nil
+}
+
+// Remove 由数据库自动维护索引，无需处理
+func (m *DatabaseEngine) Remove(id int) error {
+	return nil
+}
+
+func (m *DatabaseEngine) Search(query string, state int, pageNum int, pageSize int) ([]*Hit, int, error) {
+	results, total, err := m.articles.Search(query, state, pageNum, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*Hit, 0, len(results))
+	for _, r := range results {
+		hits = append(hits, &Hit{
+			ID:      r.ID,
+			Title:   r.Title,
+			Desc:    r.Desc,
+			Snippet: highlight(r.Content, query),
+			Score:   r.Score,
+		})
+	}
+
+	return hits, total, nil
+}
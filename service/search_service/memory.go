@@ -0,0 +1,156 @@
+package search_service
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+)
+
+// titleBoost 标题中的命中词权重
+const titleBoost = 3
+
+type memoryDoc struct {
+	title   string
+	desc    string
+	content string
+	state   int
+	length  int
+}
+
+// MemoryEngine 进程内的倒排索引搜索引擎，适用于本地开发和测试，按 TF-IDF 计算相关度
+type MemoryEngine struct {
+	mu       sync.RWMutex
+	docs     map[int]*memoryDoc
+	postings map[string]map[int]int // 词 -> 文章ID -> 加权词频
+}
+
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{
+		docs:     make(map[int]*memoryDoc),
+		postings: make(map[string]map[int]int),
+	}
+}
+
+func (m *MemoryEngine) Index(article *models.Article) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(article.ID)
+
+	freqs := make(map[string]int)
+	titleTokens := tokenize(article.Title, false)
+	contentTokens := tokenize(article.Content, false)
+	for _, t := range titleTokens {
+		freqs[t] += titleBoost
+	}
+	for _, t := range contentTokens {
+		freqs[t]++
+	}
+
+	for t, n := range freqs {
+		if m.postings[t] == nil {
+			m.postings[t] = make(map[int]int)
+		}
+		m.postings[t][article.ID] = n
+	}
+
+	m.docs[article.ID] = &memoryDoc{
+		title:   article.Title,
+		desc:    article.Desc,
+		content: article.Content,
+		state:   article.State,
+		length:  len(titleTokens) + len(contentTokens),
+	}
+	return nil
+}
+
+func (m *MemoryEngine) Remove(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(id)
+	return nil
+}
+
+func (m *MemoryEngine) remove(id int) {
+	if _, ok := m.docs[id]; !ok {
+		return
+	}
+
+	for t, docs := range m.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	delete(m.docs, id)
+}
+
+func (m *MemoryEngine) Search(query string, state int, pageNum int, pageSize int) ([]*Hit, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scores := make(map[int]float64)
+	total := float64(len(m.docs))
+	for _, t := range uniqueTokens(tokenize(query, true)) {
+		docs := m.postings[t]
+		if len(docs) == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + total/float64(len(docs)))
+		for id, n := range docs {
+			doc := m.docs[id]
+			if state >= 0 && doc.state != state {
+				continue
+			}
+			scores[id] += float64(n) / math.Sqrt(float64(doc.length+1)) * idf
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	count := len(ids)
+	start := min(pageNum, count)
+	end := count
+	if pageSize > 0 {
+		end = min(start+pageSize, count)
+	}
+
+	hits := make([]*Hit, 0, end-start)
+	for _, id := range ids[start:end] {
+		doc := m.docs[id]
+		hits = append(hits, &Hit{
+			ID:      id,
+			Title:   doc.title,
+			Desc:    doc.desc,
+			Snippet: highlight(doc.content, query),
+			Score:   scores[id],
+		})
+	}
+
+	return hits, count, nil
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	result := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
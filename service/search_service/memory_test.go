@@ -0,0 +1,151 @@
+package search_service
+
+import (
+	"testing"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+)
+
+func newArticle(id int, title, content string, state int) *models.Article {
+	article := &models.Article{Title: title, Content: content, State: state}
+	article.ID = id
+	return article
+}
+
+// searchIDs 返回搜索结果的文章ID，按相关度排序
+func searchIDs(t *testing.T, engine Engine, query string, state int) []int {
+	t.Helper()
+
+	hits, total, err := engine.Search(query, state, 0, 0)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	if total != len(hits) {
+		t.Errorf("Search(%q) total = %d, want %d", query, total, len(hits))
+	}
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemoryEngineIndex(t *testing.T) {
+	engine := NewMemoryEngine()
+	engine.Index(newArticle(1, "Gin 路由", "介绍 gin 的路由分组", 1))
+	engine.Index(newArticle(2, "Redis", "缓存与 gin 中间件", 1))
+	engine.Index(newArticle(3, "草稿", "gin draft", 0))
+
+	for _, tc := range []struct {
+		query string
+		state int
+		want  []int
+	}{
+		{query: "GIN", state: 1, want: []int{1, 2}},
+		{query: "gin", state: -1, want: []int{1, 3, 2}},
+		{query: "gin", state: 0, want: []int{3}},
+		{query: "路由", state: 1, want: []int{1}},
+		{query: "中间件", state: 1, want: []int{2}},
+		{query: "路", state: 1, want: []int{1}},
+		{query: "missing", state: -1, want: []int{}},
+	} {
+		if got := searchIDs(t, engine, tc.query, tc.state); !equalIDs(got, tc.want) {
+			t.Errorf("Search(%q, %d) = %v, want %v", tc.query, tc.state, got, tc.want)
+		}
+	}
+}
+
+func TestMemoryEngineReindexAndRemove(t *testing.T) {
+	engine := NewMemoryEngine()
+	engine.Index(newArticle(1, "Gin", "routing", 1))
+	engine.Index(newArticle(2, "Echo", "routing", 1))
+
+	// 重新索引后旧内容中的词不再命中
+	engine.Index(newArticle(1, "Fiber", "middleware", 1))
+	if got := searchIDs(t, engine, "gin", -1); len(got) != 0 {
+		t.Errorf("Search(gin) after reindex = %v, want none", got)
+	}
+	if got := searchIDs(t, engine, "fiber", -1); !equalIDs(got, []int{1}) {
+		t.Errorf("Search(fiber) after reindex = %v, want [1]", got)
+	}
+
+	// 状态变化同样通过重新索引生效
+	engine.Index(newArticle(2, "Echo", "routing", 0))
+	if got := searchIDs(t, engine, "routing", 1); len(got) != 0 {
+		t.Errorf("Search(routing, 1) after unpublishing = %v, want none", got)
+	}
+
+	if err := engine.Remove(2); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if got := searchIDs(t, engine, "echo", -1); len(got) != 0 {
+		t.Errorf("Search(echo) after remove = %v, want none", got)
+	}
+	if len(engine.docs) != 1 || len(engine.postings["routing"]) != 0 {
+		t.Errorf("index after remove has %d docs and %d routing postings, want 1 and 0", len(engine.docs), len(engine.postings["routing"]))
+	}
+
+	// 删除不存在的文章不报错
+	if err := engine.Remove(9); err != nil {
+		t.Errorf("Remove missing article: %v", err)
+	}
+}
+
+func TestMemoryEngineRanking(t *testing.T) {
+	engine := NewMemoryEngine()
+	engine.Index(newArticle(1, "Intro", "this post mentions gin once", 1))
+	engine.Index(newArticle(2, "Gin routing", "routes", 1))
+	engine.Index(newArticle(3, "Gin gin gin", "gin everywhere gin", 1))
+	engine.Index(newArticle(4, "Long", "gin "+longText(200), 1))
+
+	// 标题加权，词频越高越靠前，同样命中一次时长文档的分数更低
+	if got := searchIDs(t, engine, "gin", 1); !equalIDs(got, []int{3, 2, 1, 4}) {
+		t.Errorf("Search(gin) = %v, want [3 2 1 4]", got)
+	}
+
+	// 每个命中的词都会累加分数，其他文章的分数不变
+	scores := func(query string) map[int]float64 {
+		hits, _, err := engine.Search(query, 1, 0, 0)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", query, err)
+		}
+		result := make(map[int]float64, len(hits))
+		for _, hit := range hits {
+			result[hit.ID] = hit.Score
+		}
+		return result
+	}
+	single, multi := scores("gin"), scores("gin routes")
+	if multi[2] <= single[2] || multi[1] != single[1] || multi[3] != single[3] {
+		t.Errorf("scores for gin = %v, gin routes = %v, want only article 2 to increase", single, multi)
+	}
+
+	hits, total, err := engine.Search("gin", 1, 1, 2)
+	if err != nil || total != 4 || len(hits) != 2 || hits[0].ID != 2 {
+		t.Errorf("Search(gin) page = %d hits, total %d, %v, want 2 hits starting at article 2 of 4", len(hits), total, err)
+	}
+	if hits[0].Snippet != "routes" || hits[0].Score <= hits[1].Score {
+		t.Errorf("hits = %+v %+v, want snippet routes and descending score", hits[0], hits[1])
+	}
+}
+
+func longText(words int) string {
+	text := ""
+	for i := 0; i < words; i++ {
+		text += " filler"
+	}
+	return text
+}
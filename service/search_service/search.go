@@ -0,0 +1,219 @@
+package search_service
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/pkg/logging"
+	"github.com/3Eeeecho/go-gin-example/pkg/setting"
+)
+
+// 搜索引擎类型，通过 [app] SearchEngine 配置
+const (
+	ENGINE_DATABASE = "database"
+	ENGINE_MEMORY   = "memory"
+	// ENGINE_MYSQL 兼容旧配置，与 ENGINE_DATABASE 相同
+	ENGINE_MYSQL = "mysql"
+)
+
+// Hit 一条搜索结果
+type Hit struct {
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Desc    string  `json:"desc"`
+	Snippet string  `json:"snippet"` // 命中词使用 <em> 标记的内容片段，已做 HTML 转义
+	Score   float64 `json:"score"`
+}
+
+// Engine 文章全文搜索引擎
+type Engine interface {
+	// Index 新增或更新文章的索引
+	Index(article *models.Article) error
+	// Remove 删除文章的索引
+	Remove(id int) error
+	// Search 按相关度降序搜索文章，state 为 -1 时不过滤状态
+	Search(query string, state int, pageNum int, pageSize int) ([]*Hit, int, error)
+}
+
+// Service 文章搜索服务，文章变化时从 articles 读取文章更新索引
+type Service struct {
+	engine   Engine
+	articles models.ArticleRepository
+}
+
+// NewService 根据配置选择搜索引擎，内存引擎会从 articles 加载全部文章建立索引。
+// articles 不支持数据库搜索时（例如内存仓库）总是使用内存引擎
+func NewService(articles models.ArticleRepository) *Service {
+	searcher, ok := articles.(models.ArticleSearcher)
+	if setting.AppSetting.SearchEngine != ENGINE_MEMORY && ok {
+		return &Service{engine: NewDatabaseEngine(searcher), articles: articles}
+	}
+
+	memory := NewMemoryEngine()
+	all, err := articles.GetAll()
+	if err != nil {
+		logging.Error("search_service.NewService load articles failed:", err)
+	}
+	for _, article := range all {
+		memory.Index(article)
+	}
+	return &Service{engine: memory, articles: articles}
+}
+
+// Search 按相关度降序搜索文章，state 为 -1 时不过滤状态
+func (s *Service) Search(query string, state int, pageNum int, pageSize int) ([]*Hit, int, error) {
+	return s.engine.Search(query, state, pageNum, pageSize)
+}
+
+// Index 更新文章索引，失败只记录日志
+func (s *Service) Index(id int) {
+	article, err := s.articles.Get(id)
+	if err != nil {
+		logging.Warn(fmt.Sprintf("search index article %d failed: %v", id, err))
+		return
+	}
+	if article.ID == 0 {
+		return
+	}
+
+	if err := s.engine.Index(article); err != nil {
+		logging.Warn(fmt.Sprintf("search index article %d failed: %v", id, err))
+	}
+}
+
+// Remove 删除文章索引，失败只记录日志
+func (s *Service) Remove(id int) {
+	if err := s.engine.Remove(id); err != nil {
+		logging.Warn(fmt.Sprintf("search remove article %d failed: %v", id, err))
+	}
+}
+
+// tokenize 分词：连续的字母数字组成一个词，中日韩文字按单字和相邻双字切分。
+// 搜索时 query 为 true，多字的中日韩词组只使用双字，避免单字命中带来的噪音
+func tokenize(s string, query bool) []string {
+	var (
+		tokens []string
+		word   []rune
+		cjk    []rune
+	)
+
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+		if len(cjk) > 0 {
+			for i, r := range cjk {
+				if !query || len(cjk) == 1 {
+					tokens = append(tokens, string(r))
+				}
+				if i > 0 {
+					tokens = append(tokens, string(cjk[i-1:i+1]))
+				}
+			}
+			cjk = cjk[:0]
+		}
+	}
+
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case isCJK(r):
+			if len(word) > 0 {
+				flush()
+			}
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(cjk) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// snippetRadius 片段中命中词前后保留的字符数
+const snippetRadius = 60
+
+// highlight 截取 content 中第一个命中词附近的片段，并用 <em> 标记所有命中词
+func highlight(content, query string) string {
+	terms := strings.Fields(strings.ToLower(query))
+	runes := []rune(content)
+	lower := []rune(strings.ToLower(content))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(lower, []rune(term), 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+
+	start, end := 0, len(runes)
+	if first >= 0 {
+		start = max(first-snippetRadius, 0)
+		end = min(first+snippetRadius, len(runes))
+	} else {
+		end = min(2*snippetRadius, len(runes))
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+	for i := start; i < end; {
+		matched := 0
+		for _, term := range terms {
+			t := []rune(term)
+			if len(t) > matched && i+len(t) <= end && indexRunes(lower[i:i+len(t)], t, 0) == 0 {
+				matched = len(t)
+			}
+		}
+
+		if matched > 0 {
+			b.WriteString("<em>" + html.EscapeString(string(runes[i:i+matched])) + "</em>")
+			i += matched
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("...")
+	}
+
+	return b.String()
+}
+
+func indexRunes(s, sub []rune, from int) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := from; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
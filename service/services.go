@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/3Eeeecho/go-gin-example/models"
+	"github.com/3Eeeecho/go-gin-example/service/article_service"
+	"github.com/3Eeeecho/go-gin-example/service/auth_service"
+	"github.com/3Eeeecho/go-gin-example/service/category_service"
+	"github.com/3Eeeecho/go-gin-example/service/comment_service"
+	"github.com/3Eeeecho/go-gin-example/service/search_service"
+	"github.com/3Eeeecho/go-gin-example/service/tag_service"
+)
+
+// Services 处理函数使用的全部服务，与 models.Repositories 一一对应
+type Services struct {
+	Search     *search_service.Service
+	Articles   *article_service.Service
+	Tags       *tag_service.Service
+	Categories *category_service.Service
+	Comments   *comment_service.Service
+	Users      *auth_service.Service
+}
+
+// New 把 repos 注入到各个服务中，搜索服务和文章服务共用同一个文章仓库。
+// 内存搜索引擎会在这里从文章仓库建立索引
+func New(repos *models.Repositories) *Services {
+	search := search_service.NewService(repos.Articles)
+	return &Services{
+		Search:     search,
+		Articles:   article_service.NewService(repos.Articles, repos.Categories, search),
+		Tags:       tag_service.NewService(repos.Tags),
+		Categories: category_service.NewService(repos.Categories),
+		Comments:   comment_service.NewService(repos.Comments),
+		Users:      auth_service.NewService(repos.Users),
+	}
+}
@@ -2,7 +2,6 @@ package tag_service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -17,8 +16,21 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+// Service 标签服务依赖的仓库，由 NewService 注入
+type Service struct {
+	repo models.TagRepository
+}
+
+func NewService(tags models.TagRepository) *Service {
+	return &Service{repo: tags}
+}
+
+// Tag 一次标签操作的参数，Service 必须设置
 type Tag struct {
+	*Service
+
 	ID         int
+	IDs        []int
 	Name       string
 	CreatedBy  string
 	ModifiedBy string
@@ -29,15 +41,25 @@ type Tag struct {
 }
 
 func (t *Tag) ExistByName() (bool, error) {
-	return models.ExistTagByName(t.Name)
+	return t.repo.ExistByName(t.Name)
 }
 
 func (t *Tag) ExistByID() (bool, error) {
-	return models.ExistTagByID(t.ID)
+	return t.repo.ExistByID(t.ID)
+}
+
+// ExistByIDs 检查 IDs 中的标签是否全部存在
+func (t *Tag) ExistByIDs() (bool, error) {
+	return t.repo.ExistByIDs(t.IDs)
 }
 
 func (t *Tag) Add() error {
-	return models.AddTag(t.Name, t.State, t.CreatedBy)
+	if err := t.repo.Add(t.Name, t.State, t.CreatedBy); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	return nil
 }
 
 func (t *Tag) Edit() error {
@@ -47,48 +69,61 @@ func (t *Tag) Edit() error {
 	if t.State >= 0 {
 		data["state"] = t.State
 	}
-	return models.EditTag(t.ID, data)
+	if err := t.repo.Edit(t.ID, data); err != nil {
+		return err
+	}
+
+	t.clearCache()
+
+	// 文章数据中包含标签信息，带有该标签的文章缓存也需要清理
+	articleIDs, err := t.repo.GetArticleIDs(t.ID)
+	if err != nil {
+		logging.Warn(err)
+		return nil
+	}
+	clearArticlesCache(articleIDs)
+	return nil
 }
 
 func (t *Tag) Delete() error {
-	return models.DeleteTag(t.ID)
+	// 删除后关联关系随之删除，需要先查出受影响的文章
+	articleIDs, err := t.repo.GetArticleIDs(t.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := t.repo.Delete(t.ID); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	clearArticlesCache(articleIDs)
+	return nil
 }
 
 func (t *Tag) Count() (int, error) {
-	return models.GetTagTotal(t.getMaps())
+	return t.repo.Count(t.getMaps())
 }
 
 func (t *Tag) GetAll() ([]models.Tag, error) {
-	var (
-		tags, cacheTags []models.Tag
-	)
-
 	ctx := context.Background()
 	cache := cache_service.Tag{
+		Name:  t.Name,
 		State: t.State,
 
 		PageNum:  t.PageNum,
 		PageSize: t.PageSize,
 	}
 
-	key := cache.GetTagsKey()
-
-	if exists, _ := gredis.Exists(ctx, key); exists {
-		data, err := gredis.Get(ctx, key)
-		if err != nil {
-			logging.Info(err)
-		} else {
-			json.Unmarshal(data, &cacheTags)
-			return cacheTags, nil
-		}
-	}
-
-	tags, err := models.GetTags(t.PageNum, t.PageSize, t.getMaps())
+	version, err := gredis.GetVersion(ctx, cache.GetTagsVersionKey())
 	if err != nil {
 		return nil, err
 	}
-	gredis.Set(ctx, key, tags, 3600)
-	return tags, nil
+	cache.Version = version
+
+	return gredis.Fetch(ctx, cache.GetTagsKey(), gredis.DefaultFetchOptions.Named("tag_list"), func() ([]models.Tag, error) {
+		return t.repo.List(t.PageNum, t.PageSize, t.getMaps())
+	})
 }
 
 func (t *Tag) getMaps() interface{} {
@@ -175,7 +210,7 @@ func (t *Tag) Import(r io.Reader) error {
 			}
 			//去除导入重复tag
 			name := data[1]
-			exist, err := models.ExistTagByName(name)
+			exist, err := t.repo.ExistByName(name)
 			if err != nil {
 				return err
 			}
@@ -184,9 +219,23 @@ func (t *Tag) Import(r io.Reader) error {
 				continue
 			}
 
-			models.AddTag(name, 1, data[2])
+			t.repo.Add(name, 1, data[2])
 		}
 	}
 
+	t.clearCache()
 	return nil
 }
+
+// clearCache 删除标签缓存并使标签列表缓存失效，失败只记录日志
+func (t *Tag) clearCache() {
+	if err := cache_service.InvalidateTags(context.Background(), t.ID); err != nil {
+		logging.Warn(err)
+	}
+}
+
+func clearArticlesCache(articleIDs []int) {
+	if err := cache_service.InvalidateArticles(context.Background(), articleIDs...); err != nil {
+		logging.Warn(err)
+	}
+}